@@ -43,7 +43,7 @@ case "$OS" in
 esac
 CGO_ENABLED=0 GOOS=$GOOS GOARCH=$GOARCH go build \
   -ldflags "$ldflags" \
-  -o "./_build/my-web-template" cmd/web/web.go
+  -o "./_build/my-web-template" ./cmd/web
 echo "[***] go build done."
 
 # 根据 env_type 环境变量，复制不同的配置文件过去
@@ -1,7 +1,12 @@
 package config
 
 import (
-	"github.com/BurntSushi/toml"
+	"fmt"
+)
+
+const (
+	MigrateModeAuto  = "auto"
+	MigrateModeCheck = "check"
 )
 
 type AppConfig struct {
@@ -16,18 +21,121 @@ type AppConfig struct {
 		Password string `toml:"password"`
 		Database string `toml:"database"`
 		ShowSQL  bool   `toml:"show_sql"`
+		// SlowQueryThreshold 慢查询阈值，单位毫秒，<= 0 时使用默认值 200
+		SlowQueryThreshold int `toml:"slow_query_threshold"`
+		// SoftDeleteRetentionDays 软删除的记录保留的天数，超过后由后台任务物理删除，<= 0 时不删除
+		SoftDeleteRetentionDays int `toml:"soft_delete_retention_days"`
+		// MigrateMode 启动时数据库结构落后于代码时的处理方式：auto 自动执行迁移，check 拒绝启动
+		MigrateMode string `toml:"migrate_mode"`
 	} `toml:"database"`
 
 	Web struct {
-		ListenAddr string `toml:"listen_addr"`
+		ListenAddr      string `toml:"listen_addr"`
+		ShutdownTimeout int    `toml:"shutdown_timeout"` // 优雅关闭的超时时间，单位秒
+		DrainDelay      int    `toml:"drain_delay"`      // 收到退出信号后，/readyz 返回失败并等待多少秒再停止接收请求
+		RequestTimeout  int    `toml:"request_timeout"`  // 每个请求的处理超时，单位秒，超时后数据库查询会被取消，<= 0 时不限制
+		VersionHeader   bool   `toml:"version_header"`   // 是否在响应中添加 X-App-Version 头
+		ForceHTTP200    bool   `toml:"force_http_200"`   // 为 true 时错误响应也使用 HTTP 200，只通过 AppResult.Code 区分
 	} `toml:"web"`
+
+	Log LogConfig `toml:"log"`
+
+	Health struct {
+		Timeout       int `toml:"timeout"`          // 单个 readiness 检查的超时时间，单位秒，<= 0 时使用默认值 3
+		DiskMinFreeMB int `toml:"disk_min_free_mb"` // 日志目录所在磁盘的最小剩余空间，<= 0 时不检查
+	} `toml:"health"`
+
+	Metrics struct {
+		Enabled    bool   `toml:"enabled"`     // 默认关闭
+		Path       string `toml:"path"`        // 默认 /metrics
+		ListenAddr string `toml:"listen_addr"` // 单独的监听地址，为空时和 web 服务共用
+	} `toml:"metrics"`
+
+	Password struct {
+		Algorithm         string `toml:"algorithm"`          // argon2id, bcrypt
+		Argon2Memory      uint32 `toml:"argon2_memory"`      // 单位 KiB
+		Argon2Iterations  uint32 `toml:"argon2_iterations"`  // 迭代次数
+		Argon2Parallelism uint8  `toml:"argon2_parallelism"` // 并行度
+		BcryptCost        int    `toml:"bcrypt_cost"`
+	} `toml:"password"`
+
+	User struct {
+		DefaultRoles []string `toml:"default_roles"` // 通过注册接口创建的用户自动分配的角色
+	} `toml:"user"`
+
+	// sources 每个配置项的来源，由 LoadConfig 填充
+	sources []ValueSource
+}
+
+// LogConfig 日志配置，console 和 file 两个输出可以分别设置级别和格式
+type LogConfig struct {
+	Level      string `toml:"level"`       // 默认级别，为空时 debug 模式为 debug，否则为 info
+	StdoutOnly bool   `toml:"stdout_only"` // 只输出到 stdout，不写文件，适用于容器环境
+	// Loggers 按 logger 名称设置级别，例如 xorm = "warn"，子 logger 继承父 logger 的级别
+	Loggers map[string]string `toml:"loggers"`
+
+	Console struct {
+		Level  string `toml:"level"`  // 为空时使用 log.level
+		Format string `toml:"format"` // console, json
+	} `toml:"console"`
+
+	File struct {
+		Level      string `toml:"level"`       // 为空时使用 log.level
+		Format     string `toml:"format"`      // console, json
+		Path       string `toml:"path"`        // 相对路径相对于可执行文件所在目录，默认 logs/app.log
+		AccessPath string `toml:"access_path"` // access log 路径，默认和 path 同目录下的 access.log
+		MaxSize    int    `toml:"max_size"`    // 单个文件最大 MB
+		MaxBackups int    `toml:"max_backups"` // 保留的旧文件个数
+		MaxAge     int    `toml:"max_age"`     // 旧文件保留天数
+		Compress   bool   `toml:"compress"`    // 是否压缩旧文件
+	} `toml:"file"`
 }
 
-func LoadConfig(path string) (*AppConfig, error) {
-	var config AppConfig
-	if _, err := toml.DecodeFile(path, &config); err != nil {
-		return nil, err
+// Validate 检查配置项的取值是否合法
+func (c *AppConfig) Validate() error {
+	switch c.Database.Driver {
+	case "sqlite3", "mysql", "postgres":
+	default:
+		return fmt.Errorf("不支持的数据库驱动: %s", c.Database.Driver)
+	}
+
+	switch c.Database.MigrateMode {
+	case "", MigrateModeAuto, MigrateModeCheck:
+	default:
+		return fmt.Errorf("不支持的 migrate_mode: %s", c.Database.MigrateMode)
+	}
+
+	for _, level := range []string{c.Log.Level, c.Log.Console.Level, c.Log.File.Level} {
+		switch level {
+		case "", "debug", "info", "warn", "error":
+		default:
+			return fmt.Errorf("不支持的日志级别: %s", level)
+		}
+	}
+	for name, level := range c.Log.Loggers {
+		switch level {
+		case "debug", "info", "warn", "error":
+		default:
+			return fmt.Errorf("logger %s 的日志级别不合法: %s", name, level)
+		}
+	}
+	for _, format := range []string{c.Log.Console.Format, c.Log.File.Format} {
+		switch format {
+		case "", "console", "json":
+		default:
+			return fmt.Errorf("不支持的日志格式: %s", format)
+		}
+	}
+
+	if c.Web.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown_timeout 不能小于 0")
+	}
+	if c.Web.DrainDelay < 0 {
+		return fmt.Errorf("drain_delay 不能小于 0")
+	}
+	if c.Web.RequestTimeout < 0 {
+		return fmt.Errorf("request_timeout 不能小于 0")
 	}
 
-	return &config, nil
+	return nil
 }
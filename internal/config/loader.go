@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// EnvPrefix 环境变量覆盖配置时使用的前缀，例如 APP_DATABASE_PASSWORD 对应 database.password
+const EnvPrefix = "APP_"
+
+// SourceDefault 没有任何一层提供的配置项，保持零值
+const SourceDefault = "default"
+
+// ValueSource 记录某个配置项最终的取值来自哪一层
+type ValueSource struct {
+	Key    string // 例如 database.password
+	Source string // 配置文件路径、env:APP_XXX 或 default
+}
+
+// configField 配置结构体中的一个叶子字段
+type configField struct {
+	key   []string // toml 中的路径，例如 [database password]
+	value reflect.Value
+}
+
+// LoadConfig 分层加载配置，后面的层覆盖前面的层：
+//  1. 基础配置文件 path，例如 config.toml
+//  2. 可选的环境配置文件，文件名由 env 决定，例如 env = "prod" 时为 config.prod.toml
+//  3. APP_ 开头的环境变量，例如 APP_WEB_LISTEN_ADDR
+//
+// env 本身也可以通过 APP_ENV 覆盖，并且会用于选择第 2 层的文件。
+func LoadConfig(path string) (*AppConfig, error) {
+	var config AppConfig
+	fields := collectFields(reflect.ValueOf(&config).Elem(), nil)
+	sources := make(map[string]string, len(fields))
+
+	if err := decodeLayer(path, &config, fields, sources); err != nil {
+		return nil, err
+	}
+
+	env := config.Env
+	if value, ok := os.LookupEnv(EnvPrefix + "ENV"); ok {
+		env = value
+	}
+	if env = strings.TrimSpace(env); env != "" {
+		envPath := envConfigPath(path, env)
+		if _, err := os.Stat(envPath); err == nil {
+			if err := decodeLayer(envPath, &config, fields, sources); err != nil {
+				return nil, err
+			}
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	if err := applyEnvOverrides(fields, sources); err != nil {
+		return nil, err
+	}
+
+	config.sources = make([]ValueSource, 0, len(fields))
+	for _, field := range fields {
+		key := strings.Join(field.key, ".")
+		source, ok := sources[key]
+		if !ok {
+			source = SourceDefault
+		}
+		config.sources = append(config.sources, ValueSource{Key: key, Source: source})
+	}
+	sort.Slice(config.sources, func(i, j int) bool { return config.sources[i].Key < config.sources[j].Key })
+
+	return &config, nil
+}
+
+// Sources 返回每个配置项的来源，按 key 排序
+func (c *AppConfig) Sources() []ValueSource {
+	return c.sources
+}
+
+// envConfigPath config.toml + prod => config.prod.toml
+func envConfigPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// decodeLayer 把一个配置文件解码到 config 上，只覆盖文件中出现的配置项
+func decodeLayer(path string, config *AppConfig, fields []configField, sources map[string]string) error {
+	meta, err := toml.DecodeFile(path, config)
+	if err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+
+	for _, field := range fields {
+		if meta.IsDefined(field.key...) {
+			sources[strings.Join(field.key, ".")] = path
+		}
+	}
+	return nil
+}
+
+// applyEnvOverrides 使用环境变量覆盖配置，变量名为 APP_ 加上大写的 toml 路径，用下划线连接
+func applyEnvOverrides(fields []configField, sources map[string]string) error {
+	for _, field := range fields {
+		name := EnvPrefix + strings.ToUpper(strings.Join(field.key, "_"))
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setFieldFromString(field.value, raw); err != nil {
+			return fmt.Errorf("环境变量 %s 的值不合法: %w", name, err)
+		}
+		sources[strings.Join(field.key, ".")] = "env:" + name
+	}
+	return nil
+}
+
+// collectFields 递归收集所有带 toml tag 的叶子字段
+func collectFields(v reflect.Value, prefix []string) []configField {
+	fields := make([]configField, 0)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag := strings.Split(structField.Tag.Get("toml"), ",")[0]
+		if !structField.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+
+		key := append(append([]string{}, prefix...), tag)
+		value := v.Field(i)
+		if value.Kind() == reflect.Struct && value.Type() != reflect.TypeOf(time.Time{}) {
+			fields = append(fields, collectFields(value, key)...)
+			continue
+		}
+		fields = append(fields, configField{key: key, value: value})
+	}
+	return fields
+}
+
+func setFieldFromString(value reflect.Value, raw string) error {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("不支持的类型 %s", value.Type())
+		}
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("不支持的类型 %s", value.Type())
+	}
+	return nil
+}
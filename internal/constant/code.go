@@ -1,25 +1,66 @@
 package constant
 
+import "net/http"
+
 type ResultCode int
 
 const (
-	CodeSuccess        ResultCode = 20000
-	CodeParamError     ResultCode = 30000
-	CodeDBError        ResultCode = 40000
-	CodeRecordNotFound ResultCode = 40001
-	CodeRuntimeError   ResultCode = 50000
-	CodeUnknownError   ResultCode = 60000
+	CodeSuccess          ResultCode = 20000
+	CodeParamError       ResultCode = 30000
+	CodeAuthFailed       ResultCode = 30001
+	CodeNotLogin         ResultCode = 30002
+	CodeUserDisabled     ResultCode = 30003
+	CodeNoPermission     ResultCode = 30004
+	CodeNotFound         ResultCode = 30005
+	CodeMethodNotAllowed ResultCode = 30006
+	CodeDBError          ResultCode = 40000
+	CodeRecordNotFound   ResultCode = 40001
+	CodeRuntimeError     ResultCode = 50000
+	CodeRequestTimeout   ResultCode = 50001
+	CodeUnknownError     ResultCode = 60000
 )
 
 var ResultCodeMap = map[ResultCode]string{
-	CodeSuccess:        "Success",
-	CodeParamError:     "ParamError",
-	CodeDBError:        "DBError",
-	CodeRecordNotFound: "RecordNotFound",
-	CodeRuntimeError:   "RuntimeError",
-	CodeUnknownError:   "UnknownError",
+	CodeSuccess:          "Success",
+	CodeParamError:       "ParamError",
+	CodeAuthFailed:       "AuthFailed",
+	CodeNotLogin:         "NotLogin",
+	CodeUserDisabled:     "UserDisabled",
+	CodeNoPermission:     "NoPermission",
+	CodeNotFound:         "NotFound",
+	CodeMethodNotAllowed: "MethodNotAllowed",
+	CodeDBError:          "DBError",
+	CodeRecordNotFound:   "RecordNotFound",
+	CodeRuntimeError:     "RuntimeError",
+	CodeRequestTimeout:   "RequestTimeout",
+	CodeUnknownError:     "UnknownError",
+}
+
+// ResultCodeHTTPStatusMap 每个 ResultCode 对应的 HTTP 状态码
+var ResultCodeHTTPStatusMap = map[ResultCode]int{
+	CodeSuccess:          http.StatusOK,
+	CodeParamError:       http.StatusBadRequest,
+	CodeAuthFailed:       http.StatusUnauthorized,
+	CodeNotLogin:         http.StatusUnauthorized,
+	CodeUserDisabled:     http.StatusForbidden,
+	CodeNoPermission:     http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeMethodNotAllowed: http.StatusMethodNotAllowed,
+	CodeDBError:          http.StatusInternalServerError,
+	CodeRecordNotFound:   http.StatusNotFound,
+	CodeRuntimeError:     http.StatusInternalServerError,
+	CodeRequestTimeout:   http.StatusGatewayTimeout,
+	CodeUnknownError:     http.StatusInternalServerError,
 }
 
 func GetResultCodeName(code ResultCode) string {
 	return ResultCodeMap[code]
 }
+
+// GetResultCodeHTTPStatus 获取 ResultCode 对应的 HTTP 状态码，未定义时返回 500
+func GetResultCodeHTTPStatus(code ResultCode) int {
+	if status, ok := ResultCodeHTTPStatusMap[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
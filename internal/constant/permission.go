@@ -0,0 +1,9 @@
+package constant
+
+// PermissionAll 拥有该权限的用户可以访问所有需要权限的路由
+const PermissionAll = "*"
+
+const (
+	PermissionUserRead = "user:read"
+	PermissionLogLevel = "log:level"
+)
@@ -0,0 +1,23 @@
+package constant
+
+// session 中使用的 key
+const (
+	// SessionKeyUserId 当前登录用户的 ID
+	SessionKeyUserId = "user_id"
+	// SessionKeyPermissions 缓存的当前用户权限列表
+	SessionKeyPermissions = "permissions"
+	// SessionKeyPermissionVersion 缓存权限时对应的权限版本，版本变化时需要重新加载
+	SessionKeyPermissionVersion = "permission_version"
+)
+
+// fiber.Ctx.Locals 中使用的 key
+const (
+	// LocalsKeyUserId 通过权限中间件后，当前登录用户的 ID
+	LocalsKeyUserId = "user_id"
+	// LocalsKeyRequestId 当前请求的 request id
+	LocalsKeyRequestId = "request_id"
+	// LocalsKeyLogger 带有 request_id 字段的请求级 logger
+	LocalsKeyLogger = "logger"
+	// LocalsKeyResultCode 返回的 AppResult.code，用于统计
+	LocalsKeyResultCode = "result_code"
+)
@@ -4,16 +4,15 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
-	"github.com/alecthomas/kingpin/v2"
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/compress"
 	fiberLogger "github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/session"
 	"github.com/gofiber/storage/mysql/v2"
 	"github.com/gofiber/storage/postgres/v3"
@@ -21,10 +20,16 @@ import (
 	"go.uber.org/zap"
 	"my-web-template/internal/config"
 	"my-web-template/internal/core/appcontext"
+	"my-web-template/internal/health"
 	"my-web-template/internal/logging"
-	"my-web-template/internal/model"
+	"my-web-template/internal/metrics"
+	"my-web-template/internal/migration"
 	"my-web-template/internal/repository"
+	"my-web-template/internal/result"
+	"my-web-template/internal/security"
 	"my-web-template/internal/service"
+	"my-web-template/internal/validation"
+	"my-web-template/internal/version"
 	"my-web-template/internal/web/controller"
 	"my-web-template/internal/web/middleware"
 	"xorm.io/xorm"
@@ -32,134 +37,254 @@ import (
 )
 
 // AppComponents 包含所有初始化和组装好的应用组件
-// 方便在 bootstrap 包内部传递，或者如果 Run() 函数需要返回这些以便进行测试或进一步操作
+// 方便在 bootstrap 包内部传递，或者如果 runServe() 函数需要返回这些以便进行测试或进一步操作
 type AppComponents struct {
 	Config         *config.AppConfig
 	Logger         *zap.SugaredLogger
 	DBEngine       *xorm.Engine
 	WebApp         *fiber.App
+	MetricsApp     *fiber.App // metrics.listen_addr 不为空时单独监听的 metrics 服务
+	Health         *health.Health
+	StopPurgeJob   func() // 停止清理软删除记录的后台任务，等待进行中的清理结束
 	SessionStore   *session.Store
-	Validator      *validator.Validate
+	SessionStorage fiber.Storage
+	AccessLogFile  io.WriteCloser
+	Validator      *validation.Validator
 	UserRepo       repository.UserRepositoryInterface
 	UserService    service.UserServiceInterface
+	RoleRepo       repository.RoleRepositoryInterface
+	RoleService    service.RoleServiceInterface
 	BaseController *controller.AppBaseController
 	UserController *controller.UserController
+	LogController  *controller.LogController
 }
 
-// Run 函数负责整个应用的初始化、组装和启动
-func Run() error {
-	// 1. 解析命令行参数
-	cfgFilePath, err := parseCliArgs()
+// runServe 对应 serve 子命令，负责 web 服务的初始化、组装和启动
+func runServe(cfgFilePath string) error {
+	// 1. 加载配置文件、初始化日志、连接数据库
+	components, err := initCoreComponents(cfgFilePath)
 	if err != nil {
-		return fmt.Errorf("解析命令行参数失败：%+v", err)
+		return err
 	}
-
-	// 2. 加载配置文件
-	appConfig, err := config.LoadConfig(cfgFilePath)
-	if err != nil {
-		return fmt.Errorf("加载配置文件失败：%+v", err)
+	appConfig := components.Config
+	logger := components.Logger
+	dbEngine := components.DBEngine
+	logger.Infof("启动 %s", version.String())
+	if author := version.Info().GitAuthor; author != "" {
+		logger.Infof("构建提交的作者: %s", author)
 	}
 
-	// 3. 初始化日志
-	if err := logging.InitLogger(appConfig.Debug, "app.log"); err != nil {
-		return fmt.Errorf("初始化日志失败: %w", err)
+	// 2. 检查数据库结构
+	if err := migrateDatabase(appConfig, dbEngine, logger); err != nil {
+		logger.Errorf("数据库迁移失败: %v", err)
+		shutdown(components)
+		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
-	logger := logging.Sugar()
-	defer func() { _ = logger.Sync() }()
-	logger.Info("配置文件加载完毕，日志系统初始化完成.")
 
-	// 4. 连接数据库
-	dbEngine, err := initDatabase(appConfig, true)
-	if err != nil {
-		logger.Errorf("连接数据库失败: %v", err)
-		return fmt.Errorf("连接数据库失败: %w", err)
-	}
-	logger.Info("数据库连接成功.")
-
-	// 5. 初始化 fiber App
+	// 3. 初始化 fiber App
 	webApp := fiber.New(fiber.Config{
-		AppName:           "appname",
+		AppName:           version.AppName,
 		BodyLimit:         10 * 1024 * 1024,
 		ReadBufferSize:    10 * 1024 * 1024,
 		EnablePrintRoutes: appConfig.Debug,
-		//ErrorHandler: func(c *fiber.Ctx, err error) error {
-		//	code := fiber.StatusInternalServerError
-		//	var e *fiber.Error
-		//	if errors.As(err, &e) {
-		//		code = e.Code
-		//	}
-		//	logger.Errorf("全局错误处理器捕获错误 - Path: %s, Error: %v", c.Path(), err)
-		//	return c.Status(code).JSON(fiber.Map{"error": "服务器内部错误", "details": err.Error()}) // 生产环境可隐藏 details
-		//},
+		ErrorHandler:      middleware.ErrorHandler(appConfig.Debug, appConfig.Web.ForceHTTP200, logger),
 	})
+	components.WebApp = webApp
+
+	// 数据库连接池指标
+	if appConfig.Metrics.Enabled {
+		if err := metrics.RegisterDBStats(dbEngine, appConfig.Database.Database); err != nil {
+			shutdown(components)
+			return fmt.Errorf("注册数据库指标失败: %w", err)
+		}
+	}
 
-	// 6. 初始化核心 appcontext
+	// 4. 初始化核心 appcontext
 	appcontext.Initialize(appConfig, dbEngine, webApp, logger)
 
-	// 7. 初始化其他组件：session、validate
-	sessionStore, err := initAppSession(appConfig)
+	// 5. 初始化其他组件：session、validate
+	sessionStore, sessionStorage, err := initAppSession(appConfig)
 	if err != nil {
+		shutdown(components)
 		return fmt.Errorf("初始化 session 失败: %w", err)
 	}
+	components.SessionStore = sessionStore
+	components.SessionStorage = sessionStorage
 	logger.Infof("session 初始化成功")
-	validate := validator.New()
+	components.Health = initHealth(appConfig, dbEngine, sessionStorage)
+	validate, err := validation.New()
+	if err != nil {
+		shutdown(components)
+		return fmt.Errorf("初始化 validate 失败: %w", err)
+	}
 	logger.Infof("validate 初始化成功")
+	passwordManager, err := security.NewPasswordManagerFromConfig(appConfig)
+	if err != nil {
+		shutdown(components)
+		return fmt.Errorf("初始化密码 hash 失败: %w", err)
+	}
 
-	// 8. 依赖注入、组装
+	// 6. 依赖注入、组装
+	txManager := repository.NewTxManager(dbEngine, logger)
 	userRepo := repository.NewUserRepository(dbEngine, logger)
-	userService := service.NewUserService(userRepo, logger)
+	roleRepo := repository.NewRoleRepository(dbEngine, logger)
+	userService := service.NewUserService(userRepo, roleRepo, txManager, passwordManager, appConfig.User.DefaultRoles, logger)
+	roleService := service.NewRoleService(roleRepo, txManager, sessionStorage, logger)
 	baseController := controller.NewAppBaseController(validate, sessionStore)
 	userController := controller.NewUserController(logger, baseController, userService)
+	logController := controller.NewLogController(logger, baseController)
 	logger.Debugf("依赖注入完成")
 
-	// 9. 组装组件
-	components := &AppComponents{
-		Config:         appConfig,
-		Logger:         logger,
-		DBEngine:       dbEngine,
-		WebApp:         webApp,
-		SessionStore:   sessionStore,
-		Validator:      validate,
-		UserRepo:       userRepo,
-		UserService:    userService,
-		BaseController: baseController,
-		UserController: userController,
+	// 7. 组装组件
+	components.Validator = validate
+	components.UserRepo = userRepo
+	components.UserService = userService
+	components.RoleRepo = roleRepo
+	components.RoleService = roleService
+	components.BaseController = baseController
+	components.UserController = userController
+	components.LogController = logController
+
+	// 8. 配置 web 和路由
+	if err := setupWebApp(components); err != nil {
+		shutdown(components)
+		return fmt.Errorf("配置 web 服务失败: %w", err)
 	}
 
-	// 10. 配置 web 和路由
-	setupWebApp(components)
-
-	// (可选) 如果有其他的需要跑在后台的任务，可以在这里添加
+	// 后台任务，如果有其他的需要跑在后台的任务，可以在这里添加
+	components.StopPurgeJob = startPurgeJob(components)
 
-	// 11. 启动 Web 服务
+	// 9. 启动 Web 服务，并等待退出信号
 	listenAddr := "127.0.0.1:3000"
 	if strings.TrimSpace(appConfig.Web.ListenAddr) != "" {
 		listenAddr = strings.TrimSpace(appConfig.Web.ListenAddr)
 	}
 	logger.Infof("启动 web 服务，监听地址: %s", listenAddr)
-	return webApp.Listen(listenAddr)
+	listenErr := make(chan error, 1)
+	go func() { listenErr <- webApp.Listen(listenAddr) }()
+	if components.MetricsApp != nil {
+		metricsAddr := strings.TrimSpace(appConfig.Metrics.ListenAddr)
+		logger.Infof("启动 metrics 服务，监听地址: %s", metricsAddr)
+		go func() { listenErr <- components.MetricsApp.Listen(metricsAddr) }()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	// SIGHUP 修改日志级别，见 reloadLogLevels
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for running := true; running; {
+		select {
+		case err := <-listenErr:
+			// Listen 只有在出错时才会提前返回
+			logger.Errorf("web 服务异常退出: %v", err)
+			shutdown(components)
+			return fmt.Errorf("web 服务异常退出: %w", err)
+		case <-hup:
+			if err := reloadLogLevels(cfgFilePath, appConfig); err != nil {
+				logger.Errorf("修改日志级别失败: %v", err)
+			}
+		case sig := <-quit:
+			logger.Infof("收到信号 %s，开始优雅关闭", sig)
+			running = false
+		}
+	}
+
+	// 先让 /readyz 返回失败，等负载均衡摘除流量后再停止接收请求
+	components.Health.SetDraining()
+	if appConfig.Web.DrainDelay > 0 {
+		drainDelay := time.Duration(appConfig.Web.DrainDelay) * time.Second
+		logger.Infof("等待 %s 后停止接收请求", drainDelay)
+		select {
+		case <-time.After(drainDelay):
+		case sig := <-quit:
+			logger.Infof("再次收到信号 %s，立即关闭", sig)
+		}
+	}
+
+	return shutdown(components)
+}
+
+// reloadLogLevels 处理 SIGHUP：存在 `log level` 子命令写入的请求文件时执行请求，
+// 否则重新读取配置文件中的日志级别
+func reloadLogLevels(cfgFilePath string, appConfig *config.AppConfig) error {
+	req, err := logging.TakeLevelRequest(appConfig.Log)
+	if err != nil {
+		return err
+	}
+	if req != nil {
+		return req.Apply()
+	}
+
+	newConfig, err := loadConfig(cfgFilePath)
+	if err != nil {
+		return err
+	}
+	if err := logging.ReloadLevels(newConfig.Debug, newConfig.Log); err != nil {
+		return err
+	}
+	logging.Sugar().Infof("已重新加载配置文件中的日志级别")
+	return nil
+}
+
+// loadConfig 检查配置文件是否存在，加载并校验配置
+func loadConfig(cfgFilePath string) (*config.AppConfig, error) {
+	if _, err := os.Stat(cfgFilePath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("配置文件 '%s' 不存在", cfgFilePath)
+	}
+
+	appConfig, err := config.LoadConfig(cfgFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("加载配置文件失败：%+v", err)
+	}
+	if err := appConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("配置文件校验失败：%w", err)
+	}
+
+	return appConfig, nil
 }
 
-// parseCliArgs 解析命令行参数
-func parseCliArgs() (string, error) {
-	cli := kingpin.New("<AppName>", "<AppHelp>")                                                 // 替换为你的应用名和帮助信息
-	cfgFile := cli.Flag("config", "config file path").Short('c').Default("config.toml").String() // 改为 String(), 在后面检查文件是否存在
-	cli.HelpFlag.Short('h')
-	// 解析但不立即退出，允许主调函数处理错误
-	_, err := cli.Parse(os.Args[1:])
+// initCoreComponents 加载配置、初始化日志并连接数据库，serve 和其他需要数据库的子命令共用。
+// 返回的 components 使用完毕后需要调用 shutdown 释放。
+func initCoreComponents(cfgFilePath string) (*AppComponents, error) {
+	appConfig, err := loadConfig(cfgFilePath)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// 检查文件是否存在
-	if _, err := os.Stat(*cfgFile); os.IsNotExist(err) {
-		return "", fmt.Errorf("配置文件 '%s' 不存在", *cfgFile)
+	if err := logging.InitLogger(appConfig.Debug, appConfig.Log); err != nil {
+		return nil, fmt.Errorf("初始化日志失败: %w", err)
+	}
+	logger := logging.Sugar()
+	logger.Info("配置文件加载完毕，日志系统初始化完成.")
+	for _, source := range appConfig.Sources() {
+		logger.Debugf("配置项 %s 来自 %s", source.Key, source.Source)
+	}
+
+	// components 随着初始化的进行逐步填充，任意一步失败时都通过 shutdown 释放已经创建的资源
+	components := &AppComponents{
+		Config: appConfig,
+		Logger: logger,
+	}
+
+	dbEngine, err := initDatabase(appConfig, logger)
+	if err != nil {
+		logger.Errorf("连接数据库失败: %v", err)
+		shutdown(components)
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
-	return *cfgFile, nil
+	components.DBEngine = dbEngine
+	logger.Info("数据库连接成功.")
+
+	return components, nil
 }
 
 // initDatabase 初始化数据库
-func initDatabase(appConfig *config.AppConfig, syncTable bool) (*xorm.Engine, error) {
+func initDatabase(appConfig *config.AppConfig, logger *zap.SugaredLogger) (*xorm.Engine, error) {
 	dsn := ""
 	dbCfg := appConfig.Database
 
@@ -186,10 +311,9 @@ func initDatabase(appConfig *config.AppConfig, syncTable bool) (*xorm.Engine, er
 		return nil, fmt.Errorf("创建 XORM 引擎失败，错误: %+v", err)
 	}
 
-	// 设置 XORM 日志 (可选，但推荐)
-	// 如果你希望 XORM 的日志也使用 zap，你需要一个适配器
-	// engine.SetLogger(NewXormZapLogger(logger)) // 假设你有这样的适配器
-	// engine.SetLogger(xorm.NewSimpleLogger(os.Stdout)) // 或者使用 xorm 默认的简单日志
+	// XORM 日志输出到 zap，并检测慢查询
+	slowThreshold := time.Duration(dbCfg.SlowQueryThreshold) * time.Millisecond
+	engine.SetLogger(logging.NewXormLogger(logger, slowThreshold))
 
 	// 测试数据库连接
 	if err := engine.Ping(); err != nil {
@@ -202,21 +326,53 @@ func initDatabase(appConfig *config.AppConfig, syncTable bool) (*xorm.Engine, er
 	// set name mapper
 	engine.SetMapper(names.GonicMapper{})
 
-	// 同步表结构
-	if syncTable {
-		err = engine.Sync(
-			// TODO 指定要同步的表
-			new(model.AppUserModel),
-		)
+	return engine, nil
+}
+
+// migrateDatabase 根据 database.migrate_mode 处理未执行的迁移，表结构的变更统一写在 internal/migration 中
+func migrateDatabase(appConfig *config.AppConfig, engine *xorm.Engine, logger *zap.SugaredLogger) error {
+	migrator, err := migration.NewMigrator(engine, logger)
+	if err != nil {
+		return err
+	}
+
+	switch appConfig.Database.MigrateMode {
+	case "", config.MigrateModeAuto:
+		count, err := migrator.Up()
 		if err != nil {
-			return nil, fmt.Errorf("同步表结构失败，错误: %+v", err)
+			return err
 		}
+		logger.Infof("数据库迁移完成，本次执行 %d 个迁移", count)
+	case config.MigrateModeCheck:
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("数据库有 %d 个未执行的迁移，最早的是 %d_%s，请先执行迁移", len(pending), pending[0].Version, pending[0].Name)
+		}
+	default:
+		return fmt.Errorf("不支持的 migrate_mode: %s", appConfig.Database.MigrateMode)
 	}
-	return engine, nil
+
+	return nil
 }
 
-// initAppSession 初始化session
-func initAppSession(appConfig *config.AppConfig) (*session.Store, error) {
+// initHealth 注册 /readyz 的检查项：数据库、session storage、日志目录的磁盘空间
+func initHealth(appConfig *config.AppConfig, engine *xorm.Engine, storage fiber.Storage) *health.Health {
+	timeout := time.Duration(appConfig.Health.Timeout) * time.Second
+	h := health.NewHealth()
+	h.Register(health.NewDBChecker(engine), timeout)
+	h.Register(health.NewStorageChecker(storage), timeout)
+	if appConfig.Health.DiskMinFreeMB > 0 && !appConfig.Log.StdoutOnly {
+		logDir := filepath.Dir(logging.LogFilePath(appConfig.Log))
+		h.Register(health.NewDiskChecker("log_disk", logDir, uint64(appConfig.Health.DiskMinFreeMB)<<20), timeout)
+	}
+	return h
+}
+
+// initAppSession 初始化session，同时返回底层的 storage，便于退出时关闭
+func initAppSession(appConfig *config.AppConfig) (*session.Store, fiber.Storage, error) {
 	var storage fiber.Storage
 	switch appConfig.Database.Driver {
 	case "sqlite3":
@@ -234,7 +390,7 @@ func initAppSession(appConfig *config.AppConfig) (*session.Store, error) {
 			Password: appConfig.Database.Password, Table: "fiber_storage", // 建议指定表名
 		})
 	default:
-		return nil, fmt.Errorf("session storage 初始化失败，不支持的数据库类型 %s", appConfig.Database.Driver)
+		return nil, nil, fmt.Errorf("session storage 初始化失败，不支持的数据库类型 %s", appConfig.Database.Driver)
 	}
 
 	sessionConfig := session.ConfigDefault
@@ -245,44 +401,89 @@ func initAppSession(appConfig *config.AppConfig) (*session.Store, error) {
 	// sessionConfig.CookieHTTPOnly = true
 	// sessionConfig.CookieSameSite = "Lax"
 
-	return session.New(sessionConfig), nil
+	store := session.New(sessionConfig)
+	// session 中缓存的权限列表是 []string，需要注册给 gob
+	store.RegisterType([]string{})
+
+	return store, storage, nil
 }
 
-func setupWebApp(components *AppComponents) {
-	// 核心中间件
-	components.WebApp.Use(recover.New(recover.Config{EnableStackTrace: components.Config.Debug}))
+func setupWebApp(components *AppComponents) error {
+	// 核心中间件，RequestID 需要放在最前面，后续的中间件、ErrorHandler 和 access log 都会用到
+	components.WebApp.Use(middleware.RequestID(components.Logger))
+	// Metrics 需要在 Recover 之前，才能统计到 panic 的请求
+	if components.Config.Metrics.Enabled {
+		components.WebApp.Use(middleware.Metrics())
+	}
+	components.WebApp.Use(middleware.Recover())
+	if components.Config.Web.RequestTimeout > 0 {
+		components.WebApp.Use(middleware.Timeout(time.Duration(components.Config.Web.RequestTimeout) * time.Second))
+	}
+	components.WebApp.Use(middleware.CancelOnDisconnect())
 	components.WebApp.Use(compress.New(compress.Config{
 		Level: compress.LevelBestSpeed,
 	}))
 
-	// 设置 access log 中间件
-	accessFile := path.Join(logging.GetExecPath(), "logs", "access.log")
-	accessLogFile, err := os.OpenFile(accessFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		panic(fmt.Sprintf("打开 access.log 失败，错误 %s", err))
+	// 设置 access log 中间件，stdout_only 时只输出到 stdout
+	var accessLogOutput io.Writer = os.Stdout
+	if !components.Config.Log.StdoutOnly {
+		accessLogFile, err := logging.NewRotateWriter(components.Config.Log, logging.AccessLogFilePath(components.Config.Log))
+		if err != nil {
+			return fmt.Errorf("打开 access.log 失败，错误 %w", err)
+		}
+		components.AccessLogFile = accessLogFile
+		accessLogOutput = io.MultiWriter(os.Stdout, accessLogFile)
 	}
 	components.WebApp.Use(fiberLogger.New(fiberLogger.Config{
-		Output: io.MultiWriter(os.Stdout, accessLogFile),
-		Format: "[${time}] ${ip}:${port} ${status} - ${latency} ${method} ${path} Error: ${error}\n",
+		Output: accessLogOutput,
+		Format: "[${time}] ${locals:request_id} ${ip}:${port} ${status} - ${latency} ${method} ${path} Error: ${error}\n",
 	}))
 
-	// 健康检查路由
+	// 在响应头中带上当前版本，方便排查请求落在了哪个版本的实例上
+	if components.Config.Web.VersionHeader {
+		components.WebApp.Use(func(ctx *fiber.Ctx) error {
+			ctx.Set("X-App-Version", version.AppVersion)
+			return ctx.Next()
+		})
+	}
+
+	// 健康检查路由，/status 为兼容旧的探针保留
 	components.WebApp.Get("/status", func(ctx *fiber.Ctx) error { return ctx.SendString("ok") })
+	components.WebApp.Get("/healthz", health.LivenessHandler())
+	components.WebApp.Get("/readyz", components.Health.ReadinessHandler(components.Config.Debug))
+	// 构建信息
+	components.WebApp.Get("/version", func(ctx *fiber.Ctx) error { return ctx.JSON(result.NewSuccessResult(version.Info())) })
+
+	// 指标，配置了单独的监听地址时不挂在 web 服务上
+	if components.Config.Metrics.Enabled {
+		metricsPath := metrics.DefaultPath
+		if strings.TrimSpace(components.Config.Metrics.Path) != "" {
+			metricsPath = strings.TrimSpace(components.Config.Metrics.Path)
+		}
+		if strings.TrimSpace(components.Config.Metrics.ListenAddr) == "" {
+			components.Logger.Warnf("metrics.listen_addr 为空，指标 %s 和业务接口共用监听地址，会对外公开", metricsPath)
+			components.WebApp.Get(metricsPath, metrics.Handler())
+		} else {
+			components.MetricsApp = fiber.New(fiber.Config{
+				AppName:               version.AppName + " metrics",
+				DisableStartupMessage: true,
+			})
+			components.MetricsApp.Get(metricsPath, metrics.Handler())
+		}
+	}
 
 	// API 路由组
 	apiGroup := components.WebApp.Group("/api")
 
 	// 中间件
-	permissionMW := middleware.PermissionMiddleware(components.UserService)
-	// 如果需要给中间件动态传递参数，可以使用
-	// loginCheckMiddleware := func(requireAdmin bool) func(ctx *fiber.Ctx) error {
-	//		return middleware.LoginCheckMiddleware(a.baseController.SessionStore, userService, requireAdmin)
-	//	}
+	// 路由注册时通过 requirePermission(...) 声明需要的权限，不传参数时只要求登录
+	requirePermission := middleware.PermissionMiddleware(components.SessionStore, components.UserService, components.RoleService, components.Logger)
 	// 另外一种注册中间件方法
-	// app.WebApp.Use("/api/user", permissionMiddleware)
+	// app.WebApp.Use("/api/user", requirePermission("user:read"))
 
 	// 设置每个 controller 模块的路由
-	components.UserController.SetupRouter(apiGroup, permissionMW)
+	components.UserController.SetupRouter(apiGroup, requirePermission)
+	components.LogController.SetupRouter(apiGroup, requirePermission)
 
 	// TODO 设置前端项目
 	//app.WebApp.Use("/", filesystem.New(filesystem.Config{
@@ -298,4 +499,6 @@ func setupWebApp(components *AppComponents) {
 	//		// ....
 	//	},
 	//}))
+
+	return nil
 }
@@ -0,0 +1,56 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/kingpin/v2"
+	"my-web-template/internal/result"
+	"my-web-template/internal/version"
+)
+
+// commandHandlers 子命令全名（例如 "migrate up"）到处理函数的映射，参数是配置文件路径
+type commandHandlers map[string]func(cfgFilePath string) error
+
+// Run 解析命令行参数并执行对应的子命令，未指定子命令时执行 serve
+func Run() error {
+	cli := kingpin.New(version.AppName, "<AppHelp>")                                             // 替换为你的应用帮助信息
+	cfgFile := cli.Flag("config", "config file path").Short('c').Default("config.toml").String() // 改为 String(), 在后面检查文件是否存在
+	debug := cli.Flag("debug", "命令执行失败时输出完整的错误信息和堆栈").Bool()
+	cli.HelpFlag.Short('h')
+	cli.Version(version.String())
+
+	handlers := commandHandlers{}
+	serveCmd := cli.Command("serve", "启动 web 服务（默认）").Default()
+	handlers[serveCmd.FullCommand()] = runServe
+	registerMigrateCommands(cli, handlers)
+	registerUserCommands(cli, handlers)
+	registerConfigCommands(cli, handlers)
+	registerLogCommands(cli, handlers)
+	registerVersionCommand(cli, handlers)
+
+	// 解析但不立即退出，允许主调函数处理错误
+	command, err := cli.Parse(os.Args[1:])
+	if err != nil {
+		return fmt.Errorf("解析命令行参数失败：%+v", err)
+	}
+
+	handler, ok := handlers[command]
+	if !ok {
+		return fmt.Errorf("未知的子命令: %s", command)
+	}
+	if err := handler(*cfgFile); err != nil {
+		return commandError(err, *debug)
+	}
+	return nil
+}
+
+// commandError AppError 的 Error() 包含堆栈，命令行默认只输出 Message，指定 --debug 时输出完整信息
+func commandError(err error, debug bool) error {
+	var appErr result.AppError
+	if debug || !errors.As(err, &appErr) {
+		return err
+	}
+	return errors.New(appErr.ToAppResult().Message)
+}
@@ -0,0 +1,38 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"go.uber.org/zap"
+	"my-web-template/internal/security"
+)
+
+// registerConfigCommands config check
+func registerConfigCommands(cli *kingpin.Application, handlers commandHandlers) {
+	configCmd := cli.Command("config", "配置文件相关操作")
+
+	checkCmd := configCmd.Command("check", "检查配置文件是否合法")
+	connect := checkCmd.Flag("connect", "同时尝试连接数据库").Bool()
+	handlers[checkCmd.FullCommand()] = func(cfgFilePath string) error {
+		appConfig, err := loadConfig(cfgFilePath)
+		if err != nil {
+			return err
+		}
+		if _, err := security.NewPasswordManagerFromConfig(appConfig); err != nil {
+			return fmt.Errorf("配置文件校验失败：%w", err)
+		}
+
+		if *connect {
+			// config check 不初始化日志系统，这里不需要 xorm 的日志
+			engine, err := initDatabase(appConfig, zap.NewNop().Sugar())
+			if err != nil {
+				return fmt.Errorf("连接数据库失败: %w", err)
+			}
+			_ = engine.Close()
+		}
+
+		fmt.Printf("配置文件 %s 校验通过\n", cfgFilePath)
+		return nil
+	}
+}
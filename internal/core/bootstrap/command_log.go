@@ -0,0 +1,50 @@
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/alecthomas/kingpin/v2"
+	"my-web-template/internal/logging"
+)
+
+// registerLogCommands log level / reload，通过 SIGHUP 通知运行中的服务
+func registerLogCommands(cli *kingpin.Application, handlers commandHandlers) {
+	logCmd := cli.Command("log", "修改运行中的服务的日志级别")
+
+	levelCmd := logCmd.Command("level", "修改日志级别，不指定级别时恢复配置文件中的级别")
+	level := levelCmd.Arg("level", "debug, info, warn, error").Enum("debug", "info", "warn", "error")
+	name := levelCmd.Flag("name", "logger 名称，为空时修改全局级别").String()
+	ttl := levelCmd.Flag("ttl", "到期后自动恢复，例如 10m").String()
+	levelPid := levelCmd.Flag("pid", "服务的进程 ID").Required().Int()
+	handlers[levelCmd.FullCommand()] = func(cfgFilePath string) error {
+		appConfig, err := loadConfig(cfgFilePath)
+		if err != nil {
+			return err
+		}
+		req := logging.LevelRequest{Name: *name, Level: *level, TTL: *ttl}
+		if err := logging.WriteLevelRequest(appConfig.Log, req); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", logging.LevelRequestFilePath(appConfig.Log), err)
+		}
+		return signalReload(*levelPid)
+	}
+
+	reloadCmd := logCmd.Command("reload", "重新加载配置文件中的日志级别，清除运行时修改的级别")
+	reloadPid := reloadCmd.Flag("pid", "服务的进程 ID").Required().Int()
+	handlers[reloadCmd.FullCommand()] = func(string) error {
+		return signalReload(*reloadPid)
+	}
+}
+
+func signalReload(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("找不到进程 %d: %w", pid, err)
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("向进程 %d 发送 SIGHUP 失败: %w", pid, err)
+	}
+	fmt.Printf("已向进程 %d 发送 SIGHUP\n", pid)
+	return nil
+}
@@ -0,0 +1,89 @@
+package bootstrap
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"my-web-template/internal/migration"
+)
+
+// registerMigrateCommands migrate up / down / status / unlock
+func registerMigrateCommands(cli *kingpin.Application, handlers commandHandlers) {
+	migrateCmd := cli.Command("migrate", "数据库迁移")
+
+	upCmd := migrateCmd.Command("up", "执行所有未执行的迁移").Default()
+	handlers[upCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withMigrator(cfgFilePath, func(migrator *migration.Migrator) error {
+			count, err := migrator.Up()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("执行了 %d 个迁移\n", count)
+			return nil
+		})
+	}
+
+	downCmd := migrateCmd.Command("down", "回滚最近执行的迁移")
+	steps := downCmd.Flag("steps", "回滚的迁移数量").Short('n').Default("1").Int()
+	handlers[downCmd.FullCommand()] = func(cfgFilePath string) error {
+		if *steps <= 0 {
+			return fmt.Errorf("steps 需要大于 0")
+		}
+		return withMigrator(cfgFilePath, func(migrator *migration.Migrator) error {
+			count, err := migrator.Down(*steps)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("回滚了 %d 个迁移\n", count)
+			return nil
+		})
+	}
+
+	statusCmd := migrateCmd.Command("status", "查看迁移的执行状态")
+	handlers[statusCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withMigrator(cfgFilePath, func(migrator *migration.Migrator) error {
+			statuses, err := migrator.Status()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+			for _, status := range statuses {
+				state, appliedAt := "pending", "-"
+				if status.Applied {
+					state, appliedAt = "applied", status.AppliedAt.Format(time.RFC3339)
+				}
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+			}
+			return w.Flush()
+		})
+	}
+
+	unlockCmd := migrateCmd.Command("unlock", "强制释放迁移锁，持有锁的实例异常退出后锁会在一分钟后自动失效，一般不需要手动释放")
+	handlers[unlockCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withMigrator(cfgFilePath, func(migrator *migration.Migrator) error {
+			if err := migrator.ForceUnlock(); err != nil {
+				return err
+			}
+			fmt.Println("迁移锁已释放")
+			return nil
+		})
+	}
+}
+
+func withMigrator(cfgFilePath string, fn func(migrator *migration.Migrator) error) error {
+	components, err := initCoreComponents(cfgFilePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = shutdown(components) }()
+
+	migrator, err := migration.NewMigrator(components.DBEngine, components.Logger)
+	if err != nil {
+		return err
+	}
+	return fn(migrator)
+}
@@ -0,0 +1,228 @@
+package bootstrap
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/repository"
+	"my-web-template/internal/security"
+	"my-web-template/internal/service"
+)
+
+// registerUserCommands user create / passwd / disable / enable / grant / revoke / delete / restore / purge
+func registerUserCommands(cli *kingpin.Application, handlers commandHandlers) {
+	userCmd := cli.Command("user", "用户管理")
+
+	createCmd := userCmd.Command("create", "创建用户")
+	createUsername := createCmd.Flag("username", "用户名").Required().String()
+	createEmail := createCmd.Flag("email", "邮箱").Required().String()
+	createPassword := createCmd.Flag("password", "密码，不指定时从标准输入读取").String()
+	createRoles := createCmd.Flag("role", "分配的角色，可以指定多次").Strings()
+	handlers[createCmd.FullCommand()] = func(cfgFilePath string) error {
+		password, err := readPassword(*createPassword)
+		if err != nil {
+			return err
+		}
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, _ *service.RoleService) error {
+			user, appErr := userService.SaveUser(ctx, *createUsername, *createEmail, password, *createRoles...)
+			if appErr != nil {
+				return appErr
+			}
+			fmt.Printf("用户 %s 创建成功，ID: %d\n", user.Username, user.UserId)
+			return nil
+		})
+	}
+
+	passwdCmd := userCmd.Command("passwd", "重置用户密码")
+	passwdAccount := passwdCmd.Arg("account", "用户名或邮箱").Required().String()
+	passwdPassword := passwdCmd.Flag("password", "新密码，不指定时从标准输入读取").String()
+	handlers[passwdCmd.FullCommand()] = func(cfgFilePath string) error {
+		password, err := readPassword(*passwdPassword)
+		if err != nil {
+			return err
+		}
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, _ *service.RoleService) error {
+			if appErr := userService.ChangePassword(ctx, *passwdAccount, password); appErr != nil {
+				return appErr
+			}
+			fmt.Printf("用户 %s 的密码已重置\n", *passwdAccount)
+			return nil
+		})
+	}
+
+	disableCmd := userCmd.Command("disable", "禁用用户")
+	disableAccount := disableCmd.Arg("account", "用户名或邮箱").Required().String()
+	handlers[disableCmd.FullCommand()] = func(cfgFilePath string) error {
+		return setUserState(cfgFilePath, *disableAccount, constant.UserStatusDisabled)
+	}
+
+	enableCmd := userCmd.Command("enable", "启用用户")
+	enableAccount := enableCmd.Arg("account", "用户名或邮箱").Required().String()
+	handlers[enableCmd.FullCommand()] = func(cfgFilePath string) error {
+		return setUserState(cfgFilePath, *enableAccount, constant.UserStatusActive)
+	}
+
+	grantCmd := userCmd.Command("grant", "给用户分配角色")
+	grantAccount := grantCmd.Arg("account", "用户名或邮箱").Required().String()
+	grantRole := grantCmd.Arg("role", "角色名").Required().String()
+	handlers[grantCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+			user, appErr := userService.GetUserByAccount(ctx, *grantAccount)
+			if appErr != nil {
+				return appErr
+			}
+			if appErr := roleService.AssignRole(ctx, user.UserId, *grantRole); appErr != nil {
+				return appErr
+			}
+			fmt.Printf("已给用户 %s 分配角色 %s\n", user.Username, *grantRole)
+			return nil
+		})
+	}
+
+	revokeCmd := userCmd.Command("revoke", "取消用户的角色")
+	revokeAccount := revokeCmd.Arg("account", "用户名或邮箱").Required().String()
+	revokeRole := revokeCmd.Arg("role", "角色名").Required().String()
+	handlers[revokeCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+			user, appErr := userService.GetUserByAccount(ctx, *revokeAccount)
+			if appErr != nil {
+				return appErr
+			}
+			if appErr := roleService.UnassignRole(ctx, user.UserId, *revokeRole); appErr != nil {
+				return appErr
+			}
+			fmt.Printf("已取消用户 %s 的角色 %s\n", user.Username, *revokeRole)
+			return nil
+		})
+	}
+
+	deleteCmd := userCmd.Command("delete", "删除用户（软删除，可以通过 restore 恢复）")
+	deleteAccount := deleteCmd.Arg("account", "用户名或邮箱").Required().String()
+	handlers[deleteCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+			user, appErr := userService.DeleteUser(ctx, *deleteAccount)
+			if appErr != nil {
+				return appErr
+			}
+			if appErr := roleService.InvalidateUserPermissions(ctx, user.UserId); appErr != nil {
+				return appErr
+			}
+			fmt.Printf("用户 %s 已删除，ID: %d\n", user.Username, user.UserId)
+			return nil
+		})
+	}
+
+	restoreCmd := userCmd.Command("restore", "恢复最近删除的用户")
+	restoreUsername := restoreCmd.Arg("username", "用户名").Required().String()
+	handlers[restoreCmd.FullCommand()] = func(cfgFilePath string) error {
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+			user, appErr := userService.RestoreUser(ctx, *restoreUsername)
+			if appErr != nil {
+				return appErr
+			}
+			if appErr := roleService.InvalidateUserPermissions(ctx, user.UserId); appErr != nil {
+				return appErr
+			}
+			fmt.Printf("用户 %s 已恢复，ID: %d\n", user.Username, user.UserId)
+			return nil
+		})
+	}
+
+	purgeCmd := userCmd.Command("purge", "物理删除软删除超过指定天数的用户、角色和权限")
+	purgeDays := purgeCmd.Flag("days", "保留天数，不指定时使用 database.soft_delete_retention_days").Int()
+	handlers[purgeCmd.FullCommand()] = func(cfgFilePath string) error {
+		days := *purgeDays
+		if days <= 0 {
+			appConfig, err := loadConfig(cfgFilePath)
+			if err != nil {
+				return err
+			}
+			days = appConfig.Database.SoftDeleteRetentionDays
+		}
+		if days <= 0 {
+			return errors.New("需要通过 --days 或 database.soft_delete_retention_days 指定保留天数")
+		}
+		return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+			retention := time.Duration(days) * 24 * time.Hour
+			count, appErr := userService.PurgeDeletedUsers(ctx, retention)
+			if appErr != nil {
+				return appErr
+			}
+			fmt.Printf("物理删除了 %d 个 %d 天前删除的用户\n", count, days)
+			roles, permissions, appErr := roleService.PurgeDeletedRoles(ctx, retention)
+			if appErr != nil {
+				return appErr
+			}
+			fmt.Printf("物理删除了 %d 个角色、%d 个权限，都是 %d 天前删除的\n", roles, permissions, days)
+			return nil
+		})
+	}
+}
+
+func setUserState(cfgFilePath, account string, state uint8) error {
+	return withUserAdmin(cfgFilePath, func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error {
+		user, appErr := userService.SetUserState(ctx, account, state)
+		if appErr != nil {
+			return appErr
+		}
+		if appErr := roleService.InvalidateUserPermissions(ctx, user.UserId); appErr != nil {
+			return appErr
+		}
+		fmt.Printf("用户 %s 的状态已修改为 %s\n", account, constant.GetUserStatusName(int(state)))
+		return nil
+	})
+}
+
+// withUserAdmin 初始化用户管理需要的组件。
+// RoleService 依赖 session storage 记录权限版本，所以这里也需要初始化 session。
+func withUserAdmin(cfgFilePath string, fn func(ctx context.Context, userService *service.UserService, roleService *service.RoleService) error) error {
+	components, err := initCoreComponents(cfgFilePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = shutdown(components) }()
+
+	if err := migrateDatabase(components.Config, components.DBEngine, components.Logger); err != nil {
+		return fmt.Errorf("数据库迁移失败: %w", err)
+	}
+	_, sessionStorage, err := initAppSession(components.Config)
+	if err != nil {
+		return fmt.Errorf("初始化 session 失败: %w", err)
+	}
+	components.SessionStorage = sessionStorage
+	passwordManager, err := security.NewPasswordManagerFromConfig(components.Config)
+	if err != nil {
+		return fmt.Errorf("初始化密码 hash 失败: %w", err)
+	}
+
+	roleRepo := repository.NewRoleRepository(components.DBEngine, components.Logger)
+	txManager := repository.NewTxManager(components.DBEngine, components.Logger)
+	userService := service.NewUserService(repository.NewUserRepository(components.DBEngine, components.Logger), roleRepo, txManager, passwordManager, nil, components.Logger)
+	roleService := service.NewRoleService(roleRepo, txManager, sessionStorage, components.Logger)
+	return fn(context.Background(), userService, roleService)
+}
+
+// readPassword 命令行没有指定密码时从标准输入读取一行，避免密码出现在 shell 历史中
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+
+	fmt.Print("Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取密码失败: %w", err)
+	}
+	password = strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("密码不能为空")
+	}
+	return password, nil
+}
@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/kingpin/v2"
+	"my-web-template/internal/version"
+)
+
+// registerVersionCommand version，不需要读取配置文件
+func registerVersionCommand(cli *kingpin.Application, handlers commandHandlers) {
+	versionCmd := cli.Command("version", "显示版本信息")
+	handlers[versionCmd.FullCommand()] = func(string) error {
+		info := version.Info()
+		fmt.Printf("App name:    %s\n", info.AppName)
+		fmt.Printf("App version: %s\n", info.AppVersion)
+		fmt.Printf("Git commit:  %s\n", info.GitCommit)
+		fmt.Printf("Git author:  %s\n", info.GitAuthor)
+		fmt.Printf("Built at:    %s\n", info.BuiltAt)
+		fmt.Printf("Go version:  %s\n", info.GoVersion)
+		return nil
+	}
+}
@@ -0,0 +1,55 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+)
+
+// purgeInterval 清理软删除记录的间隔
+const purgeInterval = 24 * time.Hour
+
+// startPurgeJob database.soft_delete_retention_days > 0 时，启动后立即清理一次，之后每天清理一次
+// 超过保留期的软删除用户、角色和权限，以及它们的关联关系。
+// 返回的函数用于停止任务，由 shutdown 在关闭数据库之前调用，会等待正在进行的清理结束后再返回。
+func startPurgeJob(components *AppComponents) func() {
+	days := components.Config.Database.SoftDeleteRetentionDays
+	if days <= 0 {
+		return func() {}
+	}
+
+	retention := time.Duration(days) * 24 * time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+		for {
+			count, err := components.UserService.PurgeDeletedUsers(ctx, retention)
+			if err != nil {
+				components.Logger.Errorf("清理已删除的用户失败: %v", err)
+			} else if count > 0 {
+				components.Logger.Infof("物理删除了 %d 个 %d 天前删除的用户", count, days)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			roles, permissions, err := components.RoleService.PurgeDeletedRoles(ctx, retention)
+			if err != nil {
+				components.Logger.Errorf("清理已删除的角色和权限失败: %v", err)
+			} else if roles > 0 || permissions > 0 {
+				components.Logger.Infof("物理删除了 %d 个角色、%d 个权限，都是 %d 天前删除的", roles, permissions, days)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultShutdownTimeout 未配置 web.shutdown_timeout 时，等待进行中请求完成的默认时长
+const DefaultShutdownTimeout = 10 * time.Second
+
+// shutdown 按顺序释放应用组件：先停止后台任务和 web 服务并等待进行中的请求结束，
+// 再依次关闭 metrics 服务、session storage、数据库连接、access log 文件，最后刷新日志。
+// 未初始化的组件会被跳过，所以启动过程中任意一步失败时也可以调用。
+func shutdown(components *AppComponents) error {
+	logger := components.Logger
+	var errs []error
+
+	if components.StopPurgeJob != nil {
+		components.StopPurgeJob()
+	}
+
+	if components.WebApp != nil {
+		timeout := DefaultShutdownTimeout
+		if components.Config != nil && components.Config.Web.ShutdownTimeout > 0 {
+			timeout = time.Duration(components.Config.Web.ShutdownTimeout) * time.Second
+		}
+		logger.Infof("正在关闭 web 服务，最长等待 %s", timeout)
+		if err := components.WebApp.ShutdownWithTimeout(timeout); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 web 服务失败: %w", err))
+		}
+	}
+
+	if components.MetricsApp != nil {
+		if err := components.MetricsApp.Shutdown(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 metrics 服务失败: %w", err))
+		}
+	}
+
+	if components.SessionStorage != nil {
+		logger.Info("正在关闭 session storage")
+		if err := components.SessionStorage.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 session storage 失败: %w", err))
+		}
+	}
+
+	if components.DBEngine != nil {
+		logger.Info("正在关闭数据库连接")
+		if err := components.DBEngine.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭数据库连接失败: %w", err))
+		}
+	}
+
+	if components.AccessLogFile != nil {
+		if err := components.AccessLogFile.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 access.log 失败: %w", err))
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err != nil {
+		logger.Errorf("应用关闭过程中出现错误: %v", err)
+	} else {
+		logger.Info("应用已关闭.")
+	}
+	// 输出到 stdout 时 Sync 可能返回 invalid argument 之类的错误，这里忽略
+	_ = logger.Sync()
+
+	return err
+}
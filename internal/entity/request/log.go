@@ -0,0 +1,8 @@
+package request
+
+// SetLogLevelRequest Name 为空时修改全局级别；Level 为空时恢复配置文件中的级别；TTL 例如 10m，到期后自动恢复
+type SetLogLevelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level" validate:"omitempty,oneof=debug info warn error" normalize:"lower"`
+	TTL   string `json:"ttl"`
+}
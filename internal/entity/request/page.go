@@ -0,0 +1,260 @@
+package request
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// 列表接口统一的查询参数格式：
+//
+//	page=1                     页码，从 1 开始
+//	size=20                    每页数量
+//	sort=-created_time,id      排序，多个字段用逗号分隔，- 开头表示降序
+//	filter[username]=like:bo   过滤，格式为 操作:值，省略操作时为 eq
+//	filter[state]=in:0,1
+//	filter[created_time]=range:1700000000000,1800000000000  闭区间，省略一侧表示不限
+//
+// 每个接口通过 PageSpec 声明允许排序和过滤的字段，不在白名单中的字段返回参数错误。
+// 字段名就是数据库列名，只有白名单中的字段会出现在 SQL 中，值都通过参数绑定传递。
+
+const (
+	DefaultPageSize = 20
+	DefaultMaxSize  = 100
+	// maxInValues in 过滤最多允许的值的个数
+	maxInValues = 100
+)
+
+// FilterOp 过滤操作
+type FilterOp string
+
+const (
+	FilterEq    FilterOp = "eq"
+	FilterLike  FilterOp = "like"  // 包含
+	FilterRange FilterOp = "range" // 闭区间
+	FilterIn    FilterOp = "in"
+)
+
+// FieldType 过滤字段的类型，决定值如何解析
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+)
+
+// FilterField 允许过滤的字段
+type FilterField struct {
+	Type FieldType
+	Ops  []FilterOp
+}
+
+// PageSpec 列表接口允许的排序、过滤字段和分页大小
+type PageSpec struct {
+	SortFields   []string
+	FilterFields map[string]FilterField
+	DefaultSort  string // 格式和 sort 参数相同，为空时按 id 升序
+	MaxSize      int    // <= 0 时使用 DefaultMaxSize
+}
+
+// SortField 排序字段
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Filter 过滤条件。range 时 Values 固定两个元素，nil 表示这一侧不限
+type Filter struct {
+	Column string
+	Op     FilterOp
+	Values []any
+}
+
+// PageRequest 解析和校验之后的列表查询参数，只能通过 ParsePageRequest 生成，不能从 body 绑定
+type PageRequest struct {
+	Page    int         `json:"-" xml:"-" form:"-"`
+	Size    int         `json:"-" xml:"-" form:"-"`
+	Sorts   []SortField `json:"-" xml:"-" form:"-"`
+	Filters []Filter    `json:"-" xml:"-" form:"-"`
+}
+
+// SetPageRequest 嵌入了 PageRequest 的列表请求通过它实现 PageQuery
+func (p *PageRequest) SetPageRequest(page *PageRequest) {
+	*p = *page
+}
+
+// PageQuery 列表接口的请求类型嵌入 PageRequest 并实现 PageSpec 后，controller 会按 PageSpec 解析分页参数：
+//
+//	type UserListRequest struct {
+//		PageRequest
+//	}
+//
+//	func (r *UserListRequest) PageSpec() PageSpec { return UserListSpec }
+type PageQuery interface {
+	PageSpec() PageSpec
+	SetPageRequest(page *PageRequest)
+}
+
+// Offset 当前页第一条记录的偏移量
+func (p *PageRequest) Offset() int {
+	return (p.Page - 1) * p.Size
+}
+
+// ParsePageRequest 按 spec 解析查询参数，queries 通常来自 fiber.Ctx.Queries()
+func ParsePageRequest(queries map[string]string, spec PageSpec) (*PageRequest, error) {
+	maxSize := spec.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+
+	req := &PageRequest{Page: 1, Size: min(DefaultPageSize, maxSize)}
+	var err error
+	if raw, ok := queries["page"]; ok {
+		if req.Page, err = strconv.Atoi(raw); err != nil || req.Page < 1 {
+			return nil, fmt.Errorf("page 需要是大于 0 的整数")
+		}
+	}
+	if raw, ok := queries["size"]; ok {
+		if req.Size, err = strconv.Atoi(raw); err != nil || req.Size < 1 || req.Size > maxSize {
+			return nil, fmt.Errorf("size 需要是 1 到 %d 之间的整数", maxSize)
+		}
+	}
+	// 保证 Offset 不会溢出成负数
+	if req.Page-1 > math.MaxInt/req.Size {
+		return nil, fmt.Errorf("page 不能超过 %d", math.MaxInt/req.Size+1)
+	}
+
+	sort := queries["sort"]
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	if req.Sorts, err = parseSorts(sort, spec.SortFields); err != nil {
+		return nil, err
+	}
+
+	for key, raw := range queries {
+		column, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		column, ok = strings.CutSuffix(column, "]")
+		if !ok {
+			return nil, fmt.Errorf("过滤参数 %s 格式不正确，需要形如 filter[字段]", key)
+		}
+		filter, err := parseFilter(column, raw, spec.FilterFields)
+		if err != nil {
+			return nil, err
+		}
+		req.Filters = append(req.Filters, filter)
+	}
+	// map 的遍历顺序不固定，排序后生成的 SQL 才稳定
+	slices.SortFunc(req.Filters, func(a, b Filter) int { return strings.Compare(a.Column, b.Column) })
+
+	return req, nil
+}
+
+// parseSorts 解析排序字段，没有按 id 排序时追加 id 升序，保证分页结果稳定
+func parseSorts(raw string, allowed []string) ([]SortField, error) {
+	sorts := make([]SortField, 0)
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		sort := SortField{Column: part}
+		if column, desc := strings.CutPrefix(part, "-"); desc {
+			sort = SortField{Column: column, Desc: true}
+		}
+		if !slices.Contains(allowed, sort.Column) && sort.Column != "id" {
+			return nil, fmt.Errorf("不支持按 %s 排序", sort.Column)
+		}
+		if seen[sort.Column] {
+			return nil, fmt.Errorf("排序字段 %s 重复", sort.Column)
+		}
+		seen[sort.Column] = true
+		sorts = append(sorts, sort)
+	}
+	if !seen["id"] {
+		sorts = append(sorts, SortField{Column: "id"})
+	}
+
+	return sorts, nil
+}
+
+func parseFilter(column, raw string, allowed map[string]FilterField) (Filter, error) {
+	field, ok := allowed[column]
+	if !ok {
+		return Filter{}, fmt.Errorf("不支持按 %s 过滤", column)
+	}
+
+	op, value := FilterEq, raw
+	if prefix, rest, found := strings.Cut(raw, ":"); found && isFilterOp(prefix) {
+		op, value = FilterOp(prefix), rest
+	}
+	if !slices.Contains(field.Ops, op) {
+		return Filter{}, fmt.Errorf("字段 %s 不支持 %s 过滤", column, op)
+	}
+
+	filter := Filter{Column: column, Op: op}
+	switch op {
+	case FilterEq, FilterLike:
+		v, err := parseFilterValue(column, value, field.Type)
+		if err != nil {
+			return Filter{}, err
+		}
+		filter.Values = []any{v}
+	case FilterRange:
+		lower, upper, found := strings.Cut(value, ",")
+		if !found || (lower == "" && upper == "") {
+			return Filter{}, fmt.Errorf("字段 %s 的 range 过滤需要形如 range:最小值,最大值", column)
+		}
+		filter.Values = []any{nil, nil}
+		for i, bound := range []string{lower, upper} {
+			if bound == "" {
+				continue
+			}
+			v, err := parseFilterValue(column, bound, field.Type)
+			if err != nil {
+				return Filter{}, err
+			}
+			filter.Values[i] = v
+		}
+	case FilterIn:
+		parts := strings.Split(value, ",")
+		if len(parts) > maxInValues {
+			return Filter{}, fmt.Errorf("字段 %s 的 in 过滤最多 %d 个值", column, maxInValues)
+		}
+		for _, part := range parts {
+			v, err := parseFilterValue(column, part, field.Type)
+			if err != nil {
+				return Filter{}, err
+			}
+			filter.Values = append(filter.Values, v)
+		}
+	}
+
+	return filter, nil
+}
+
+func parseFilterValue(column, raw string, fieldType FieldType) (any, error) {
+	raw = strings.TrimSpace(raw)
+	if fieldType == FieldInt {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s 的值 %q 不是整数", column, raw)
+		}
+		return v, nil
+	}
+	return raw, nil
+}
+
+func isFilterOp(op string) bool {
+	switch FilterOp(op) {
+	case FilterEq, FilterLike, FilterRange, FilterIn:
+		return true
+	}
+	return false
+}
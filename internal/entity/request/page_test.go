@@ -0,0 +1,173 @@
+package request
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testSpec = PageSpec{
+	SortFields: []string{"username", "created_time"},
+	FilterFields: map[string]FilterField{
+		"id":           {Type: FieldInt, Ops: []FilterOp{FilterIn}},
+		"username":     {Type: FieldString, Ops: []FilterOp{FilterEq, FilterLike}},
+		"state":        {Type: FieldInt, Ops: []FilterOp{FilterEq, FilterIn}},
+		"created_time": {Type: FieldInt, Ops: []FilterOp{FilterRange}},
+	},
+	DefaultSort: "-created_time",
+	MaxSize:     50,
+}
+
+func TestParsePageRequestPaging(t *testing.T) {
+	tests := []struct {
+		name     string
+		queries  map[string]string
+		spec     PageSpec
+		wantPage int
+		wantSize int
+		wantErr  bool
+	}{
+		{"defaults", map[string]string{}, testSpec, 1, DefaultPageSize, false},
+		{"explicit", map[string]string{"page": "3", "size": "10"}, testSpec, 3, 10, false},
+		{"max size", map[string]string{"size": "50"}, testSpec, 1, 50, false},
+		{"default max size", map[string]string{"size": "100"}, PageSpec{}, 1, 100, false},
+		{"default size capped by max size", map[string]string{}, PageSpec{MaxSize: 5}, 1, 5, false},
+		{"size over max", map[string]string{"size": "51"}, testSpec, 0, 0, true},
+		{"size zero", map[string]string{"size": "0"}, testSpec, 0, 0, true},
+		{"page zero", map[string]string{"page": "0"}, testSpec, 0, 0, true},
+		{"page negative", map[string]string{"page": "-1"}, testSpec, 0, 0, true},
+		{"page not a number", map[string]string{"page": "one"}, testSpec, 0, 0, true},
+		{"page at offset limit", map[string]string{"page": strconv.Itoa(math.MaxInt/10 + 1), "size": "10"}, testSpec, math.MaxInt/10 + 1, 10, false},
+		{"page overflow", map[string]string{"page": strconv.Itoa(math.MaxInt)}, testSpec, 0, 0, true},
+		{"page overflow with size", map[string]string{"page": strconv.Itoa(math.MaxInt/10 + 2), "size": "10"}, testSpec, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParsePageRequest(tt.queries, tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", req)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.Page != tt.wantPage || req.Size != tt.wantSize {
+				t.Fatalf("page, size = %d, %d; want %d, %d", req.Page, req.Size, tt.wantPage, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestParsePageRequestSorts(t *testing.T) {
+	tests := []struct {
+		name    string
+		sort    string
+		want    []SortField
+		wantErr bool
+	}{
+		{"default sort", "", []SortField{{"created_time", true}, {"id", false}}, false},
+		{"multiple fields", "username,-created_time", []SortField{{"username", false}, {"created_time", true}, {"id", false}}, false},
+		{"explicit id", "-id", []SortField{{"id", true}}, false},
+		{"spaces and empty parts", " username , ,", []SortField{{"username", false}, {"id", false}}, false},
+		{"unknown field", "password", nil, true},
+		{"injection", "username;DROP TABLE app_user", nil, true},
+		{"duplicate field", "username,-username", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := map[string]string{}
+			if tt.sort != "" {
+				queries["sort"] = tt.sort
+			}
+			req, err := ParsePageRequest(queries, testSpec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", req.Sorts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(req.Sorts, tt.want) {
+				t.Fatalf("sorts = %+v, want %+v", req.Sorts, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePageRequestFilters(t *testing.T) {
+	tooManyIds := "in:" + strings.TrimSuffix(strings.Repeat("1,", maxInValues+1), ",")
+	maxIds := "in:" + strings.TrimSuffix(strings.Repeat("1,", maxInValues), ",")
+
+	tests := []struct {
+		name    string
+		key     string
+		raw     string
+		want    Filter
+		wantErr bool
+	}{
+		{"eq without op", "filter[username]", "bob", Filter{"username", FilterEq, []any{"bob"}}, false},
+		{"explicit eq", "filter[username]", "eq:bob", Filter{"username", FilterEq, []any{"bob"}}, false},
+		{"like", "filter[username]", "like:bo", Filter{"username", FilterLike, []any{"bo"}}, false},
+		{"unknown op is part of the value", "filter[username]", "gt:bob", Filter{"username", FilterEq, []any{"gt:bob"}}, false},
+		{"int eq", "filter[state]", "1", Filter{"state", FilterEq, []any{int64(1)}}, false},
+		{"int in", "filter[state]", "in:1, 2", Filter{"state", FilterIn, []any{int64(1), int64(2)}}, false},
+		{"range", "filter[created_time]", "range:1,2", Filter{"created_time", FilterRange, []any{int64(1), int64(2)}}, false},
+		{"range lower only", "filter[created_time]", "range:1,", Filter{"created_time", FilterRange, []any{int64(1), nil}}, false},
+		{"range upper only", "filter[created_time]", "range:,2", Filter{"created_time", FilterRange, []any{nil, int64(2)}}, false},
+		{"in at limit", "filter[id]", maxIds, Filter{}, false},
+		{"in over limit", "filter[id]", tooManyIds, Filter{}, true},
+		{"range without bounds", "filter[created_time]", "range:,", Filter{}, true},
+		{"range without comma", "filter[created_time]", "range:1", Filter{}, true},
+		{"range not a number", "filter[created_time]", "range:a,2", Filter{}, true},
+		{"op not allowed", "filter[username]", "in:a,b", Filter{}, true},
+		{"int not a number", "filter[state]", "active", Filter{}, true},
+		{"unknown field", "filter[password]", "x", Filter{}, true},
+		{"missing bracket", "filter[username", "bob", Filter{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := ParsePageRequest(map[string]string{tt.key: tt.raw}, testSpec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got %+v", req.Filters)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(req.Filters) != 1 {
+				t.Fatalf("filters = %+v, want exactly one", req.Filters)
+			}
+			if tt.want.Column != "" && !reflect.DeepEqual(req.Filters[0], tt.want) {
+				t.Fatalf("filter = %+v, want %+v", req.Filters[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePageRequestFilterOrder(t *testing.T) {
+	req, err := ParsePageRequest(map[string]string{
+		"filter[username]": "bob",
+		"filter[state]":    "1",
+		"filter[id]":       "in:1",
+		"other":            "ignored",
+	}, testSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	columns := make([]string, 0, len(req.Filters))
+	for _, filter := range req.Filters {
+		columns = append(columns, filter.Column)
+	}
+	if want := []string{"id", "state", "username"}; !reflect.DeepEqual(columns, want) {
+		t.Fatalf("columns = %v, want %v", columns, want)
+	}
+}
@@ -1,16 +1,40 @@
 package request
 
+// LoginRequest Account 可以是用户名或邮箱，不区分大小写。
+// 密码不做规范化，按原样计算 hash；早期版本保存的是去掉前后空白的密码，登录时会兼容
 type LoginRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Account  string `json:"account" validate:"required" normalize:"nfc"`
+	Password string `json:"password" validate:"required" normalize:"-"`
 }
 
 type RegisterRequest struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username string `json:"username" validate:"required,excludes=@" normalize:"nfc,lower"`
+	Email    string `json:"email" validate:"required" normalize:"nfc,lower"`
+	Password string `json:"password" validate:"required" normalize:"-"`
 }
 
 type GetUserInfoRequest struct {
-	Username string `json:"username" validate:"required"`
+	Username string `query:"username" validate:"required"`
+}
+
+// UserListSpec 用户列表允许的排序和过滤字段
+var UserListSpec = PageSpec{
+	SortFields: []string{"username", "created_time"},
+	FilterFields: map[string]FilterField{
+		"id":           {Type: FieldInt, Ops: []FilterOp{FilterIn}},
+		"username":     {Type: FieldString, Ops: []FilterOp{FilterEq, FilterLike}},
+		"email":        {Type: FieldString, Ops: []FilterOp{FilterLike}},
+		"state":        {Type: FieldInt, Ops: []FilterOp{FilterEq, FilterIn}},
+		"created_time": {Type: FieldInt, Ops: []FilterOp{FilterRange}},
+	},
+	DefaultSort: "-id",
+}
+
+// UserListRequest 用户列表的分页、排序和过滤参数
+type UserListRequest struct {
+	PageRequest
+}
+
+func (r *UserListRequest) PageSpec() PageSpec {
+	return UserListSpec
 }
@@ -0,0 +1,16 @@
+package vo
+
+// PageVO 列表接口返回的分页数据
+type PageVO[T any] struct {
+	Items []T   `json:"items"`
+	Total int64 `json:"total"`
+	Page  int   `json:"page"`
+	Size  int   `json:"size"`
+}
+
+func NewPageVO[T any](items []T, total int64, page, size int) *PageVO[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	return &PageVO[T]{Items: items, Total: total, Page: page, Size: size}
+}
@@ -0,0 +1,7 @@
+package vo
+
+type RoleVO struct {
+	RoleId      uint64 `json:"role_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"xorm.io/xorm"
+)
+
+// DBChecker 检查数据库是否可以连接
+type DBChecker struct {
+	engine *xorm.Engine
+}
+
+func NewDBChecker(engine *xorm.Engine) *DBChecker {
+	return &DBChecker{engine: engine}
+}
+
+func (c *DBChecker) Name() string {
+	return "database"
+}
+
+func (c *DBChecker) Check(ctx context.Context) error {
+	return c.engine.PingContext(ctx)
+}
+
+// StorageChecker 对 session storage 做一次写入、读取、删除
+type StorageChecker struct {
+	storage fiber.Storage
+}
+
+func NewStorageChecker(storage fiber.Storage) *StorageChecker {
+	return &StorageChecker{storage: storage}
+}
+
+func (c *StorageChecker) Name() string {
+	return "session_storage"
+}
+
+func (c *StorageChecker) Check(ctx context.Context) error {
+	key := "health:" + uuid.NewString()
+	value := []byte(key)
+	if err := c.storage.Set(key, value, 0); err != nil {
+		return fmt.Errorf("写入失败: %w", err)
+	}
+	defer func() { _ = c.storage.Delete(key) }()
+
+	got, err := c.storage.Get(key)
+	if err != nil {
+		return fmt.Errorf("读取失败: %w", err)
+	}
+	if !bytes.Equal(got, value) {
+		return fmt.Errorf("读取的值和写入的值不一致")
+	}
+	return nil
+}
+
+// DiskChecker 检查目录所在磁盘的剩余空间，用于日志目录
+type DiskChecker struct {
+	name         string
+	dir          string
+	minFreeBytes uint64
+}
+
+func NewDiskChecker(name, dir string, minFreeBytes uint64) *DiskChecker {
+	return &DiskChecker{name: name, dir: dir, minFreeBytes: minFreeBytes}
+}
+
+func (c *DiskChecker) Name() string {
+	return c.name
+}
+
+func (c *DiskChecker) Check(ctx context.Context) error {
+	free, err := freeBytes(c.dir)
+	if err != nil {
+		return err
+	}
+	if free < c.minFreeBytes {
+		return fmt.Errorf("%s 剩余空间 %d MB，低于 %d MB", c.dir, free>>20, c.minFreeBytes>>20)
+	}
+	return nil
+}
+
+var (
+	_ Checker = (*DBChecker)(nil)
+	_ Checker = (*StorageChecker)(nil)
+	_ Checker = (*DiskChecker)(nil)
+)
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package health
+
+import "math"
+
+// freeBytes 其他平台暂不检查磁盘空间
+func freeBytes(string) (uint64, error) {
+	return math.MaxUint64, nil
+}
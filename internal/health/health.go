@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DefaultTimeout 未指定超时时间时，单个检查的最长执行时间
+const DefaultTimeout = 3 * time.Second
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// errDraining 服务正在优雅关闭，此时 readiness 检查直接失败，让负载均衡停止转发新请求
+var errDraining = errors.New("服务正在关闭")
+
+// Checker readiness 检查项，Check 需要在 ctx 结束时尽快返回
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckResult 单个检查项的结果
+type CheckResult struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report readiness 检查的结果，所有检查项都通过时 Status 为 ok
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+type registeredChecker struct {
+	checker Checker
+	timeout time.Duration
+}
+
+// Health 管理 readiness 检查项，以及服务是否正在关闭
+type Health struct {
+	mu       sync.RWMutex
+	checkers []registeredChecker
+	draining atomic.Bool
+}
+
+func NewHealth() *Health {
+	return &Health{}
+}
+
+// Register 注册检查项，timeout <= 0 时使用 DefaultTimeout
+func (h *Health) Register(checker Checker, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkers = append(h.checkers, registeredChecker{checker: checker, timeout: timeout})
+}
+
+// SetDraining 标记服务正在关闭，之后的 readiness 检查都会失败
+func (h *Health) SetDraining() {
+	h.draining.Store(true)
+}
+
+// Ready 并发执行所有检查项，每个检查项单独计算超时
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.draining.Load() {
+		return Report{
+			Status: StatusDraining,
+			Checks: []CheckResult{{Name: "shutdown", Status: StatusFail, Error: errDraining.Error(), Duration: "0s"}},
+		}
+	}
+
+	h.mu.RLock()
+	checkers := append([]registeredChecker{}, h.checkers...)
+	h.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make([]CheckResult, len(checkers))}
+	var wg sync.WaitGroup
+	for i, registered := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, registered)
+		}()
+	}
+	wg.Wait()
+
+	for _, check := range report.Checks {
+		if check.Status != StatusOK {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
+
+// runCheck 执行单个检查项；Check 不响应 ctx 时也会在超时后返回，检查本身在后台继续执行
+func runCheck(ctx context.Context, registered registeredChecker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, registered.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- registered.checker.Check(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Name: registered.checker.Name(), Status: StatusOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler 进程存活即返回 200，不检查任何依赖，避免依赖故障导致 Kubernetes 反复重启 Pod
+func LivenessHandler() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		return ctx.JSON(fiber.Map{"status": StatusOK})
+	}
+}
+
+// ReadinessHandler 所有检查项通过时返回 200，否则返回 503。
+// 非 debug 模式下不返回错误详情，错误信息中可能包含数据库地址等内容。
+func (h *Health) ReadinessHandler(debug bool) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		report := h.Ready(ctx.UserContext())
+		status := fiber.StatusOK
+		if report.Status != StatusOK {
+			status = fiber.StatusServiceUnavailable
+		}
+		if !debug {
+			for i := range report.Checks {
+				if report.Checks[i].Error != "" && report.Checks[i].Name != "shutdown" {
+					report.Checks[i].Error = "check failed"
+				}
+			}
+		}
+		return ctx.Status(status).JSON(report)
+	}
+}
@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// WithLogger 把 logger（通常是带有 request_id 的子 logger）放到 context 中
+func WithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 获取 context 中的 logger，没有时返回全局 logger。
+// service 和 repository 中应优先使用这个方法，这样日志中会带上当前请求的 request_id。
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok && logger != nil {
+			return logger
+		}
+	}
+	return Sugar()
+}
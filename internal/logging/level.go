@@ -0,0 +1,413 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"my-web-template/internal/config"
+)
+
+// 运行时日志级别。
+// 每个输出（console、file）使用一个 zap.AtomicLevel，修改全局级别时同时修改所有输出的级别；
+// 通过 Named 创建的 logger 可以单独设置级别，子 logger（例如 xorm.session）会继承父 logger 的级别。
+// 运行时修改的级别可以设置 TTL，到期后自动恢复为配置文件中的级别。
+
+const (
+	SinkConsole = "console"
+	SinkFile    = "file"
+)
+
+const LevelRequestFilename = "log-level.json"
+
+// sinkLevel 一个输出当前的级别和配置文件中的级别
+type sinkLevel struct {
+	name       string
+	configured zapcore.Level
+	level      zap.AtomicLevel
+}
+
+// namedLevels 按 logger 名称设置的级别，写入时整体替换，日志输出时只读
+type namedLevels struct {
+	levels map[string]zapcore.Level
+	min    zapcore.Level
+}
+
+type levelRegistry struct {
+	mu    sync.Mutex
+	sinks []*sinkLevel
+	// configuredNamed 配置文件 [log.loggers] 中的级别，恢复时使用
+	configuredNamed map[string]zapcore.Level
+	named           atomic.Pointer[namedLevels]
+	// timers TTL 到期后恢复级别的定时器，key 为 logger 名称，全局级别为空字符串
+	timers map[string]*revertTimer
+}
+
+var levels = &levelRegistry{timers: map[string]*revertTimer{}}
+
+// LevelSnapshot 当前生效的日志级别
+type LevelSnapshot struct {
+	Sinks   map[string]string    `json:"sinks"`
+	Loggers map[string]string    `json:"loggers"`
+	Reverts map[string]time.Time `json:"reverts,omitempty"` // 设置了 TTL 的级别恢复的时间，全局级别的 key 为 "*"
+}
+
+// Named 创建一个有名称的 logger，可以通过 SetLevel 单独设置它的级别
+func Named(name string) *zap.SugaredLogger {
+	return Sugar().Named(name)
+}
+
+// SetLevel 修改日志级别，name 为空时修改全局级别，否则修改对应名称的 logger 的级别。
+// ttl 大于 0 时，到期后自动恢复为配置文件中的级别。
+func SetLevel(name string, level zapcore.Level, ttl time.Duration) error {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	if len(levels.sinks) == 0 {
+		return fmt.Errorf("日志系统未初始化")
+	}
+
+	if name == "" {
+		for _, sink := range levels.sinks {
+			sink.level.SetLevel(level)
+		}
+	} else {
+		named := levels.copyNamed()
+		named[name] = level
+		levels.storeNamed(named)
+	}
+
+	levels.scheduleRevert(name, ttl)
+	return nil
+}
+
+// ResetLevel 恢复配置文件中的级别，name 为空时恢复全局级别
+func ResetLevel(name string) {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	levels.reset(name)
+	levels.scheduleRevert(name, 0)
+}
+
+// ReloadLevels 重新应用配置文件中的级别，会清除所有运行时修改的级别
+func ReloadLevels(debug bool, cfg config.LogConfig) error {
+	configured, err := parseSinkLevels(debug, cfg)
+	if err != nil {
+		return err
+	}
+	named, err := parseNamedLevels(cfg.Loggers)
+	if err != nil {
+		return err
+	}
+
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	for _, timer := range levels.timers {
+		timer.Stop()
+	}
+	levels.timers = map[string]*revertTimer{}
+
+	for _, sink := range levels.sinks {
+		if level, ok := configured[sink.name]; ok {
+			sink.configured = level
+			sink.level.SetLevel(level)
+		}
+	}
+	levels.configuredNamed = named
+	levels.storeNamed(named)
+	return nil
+}
+
+// Levels 返回当前生效的日志级别
+func Levels() LevelSnapshot {
+	levels.mu.Lock()
+	defer levels.mu.Unlock()
+
+	snapshot := LevelSnapshot{
+		Sinks:   map[string]string{},
+		Loggers: map[string]string{},
+		Reverts: map[string]time.Time{},
+	}
+	for _, sink := range levels.sinks {
+		snapshot.Sinks[sink.name] = sink.level.Level().String()
+	}
+	if named := levels.named.Load(); named != nil {
+		for name, level := range named.levels {
+			snapshot.Loggers[name] = level.String()
+		}
+	}
+	for name, timer := range levels.timers {
+		if name == "" {
+			name = "*"
+		}
+		snapshot.Reverts[name] = timer.deadline
+	}
+	return snapshot
+}
+
+// register 由 InitLogger 调用，登记各个输出的级别
+func (r *levelRegistry) register(sinks []*sinkLevel, named map[string]zapcore.Level) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, timer := range r.timers {
+		timer.Stop()
+	}
+	r.timers = map[string]*revertTimer{}
+	r.sinks = sinks
+	r.configuredNamed = named
+	r.storeNamed(named)
+}
+
+// reset 调用方需要持有锁
+func (r *levelRegistry) reset(name string) {
+	if name == "" {
+		for _, sink := range r.sinks {
+			sink.level.SetLevel(sink.configured)
+		}
+		return
+	}
+
+	named := r.copyNamed()
+	if level, ok := r.configuredNamed[name]; ok {
+		named[name] = level
+	} else {
+		delete(named, name)
+	}
+	r.storeNamed(named)
+}
+
+// scheduleRevert 取消 name 之前的恢复定时器，ttl 大于 0 时重新设置，调用方需要持有锁
+func (r *levelRegistry) scheduleRevert(name string, ttl time.Duration) {
+	if timer, ok := r.timers[name]; ok {
+		timer.Stop()
+		delete(r.timers, name)
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	timer := &revertTimer{deadline: time.Now().Add(ttl)}
+	timer.Timer = time.AfterFunc(ttl, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// 定时器可能已经被新的设置替换
+		if r.timers[name] != timer {
+			return
+		}
+		delete(r.timers, name)
+		r.reset(name)
+		Sugar().Infof("日志级别 TTL 到期，已恢复 %s 的级别", levelTargetName(name))
+	})
+	r.timers[name] = timer
+}
+
+func (r *levelRegistry) copyNamed() map[string]zapcore.Level {
+	named := map[string]zapcore.Level{}
+	if current := r.named.Load(); current != nil {
+		for name, level := range current.levels {
+			named[name] = level
+		}
+	}
+	return named
+}
+
+func (r *levelRegistry) storeNamed(levels map[string]zapcore.Level) {
+	if len(levels) == 0 {
+		r.named.Store(nil)
+		return
+	}
+	named := &namedLevels{levels: levels, min: zapcore.FatalLevel}
+	for _, level := range levels {
+		if level < named.min {
+			named.min = level
+		}
+	}
+	r.named.Store(named)
+}
+
+// levelFor 按名称查找 logger 的级别，依次匹配 a.b.c、a.b、a
+func (n *namedLevels) levelFor(loggerName string) (zapcore.Level, bool) {
+	for name := loggerName; name != ""; {
+		if level, ok := n.levels[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return 0, false
+}
+
+type revertTimer struct {
+	*time.Timer
+	deadline time.Time
+}
+
+func levelTargetName(name string) string {
+	if name == "" {
+		return "全局日志"
+	}
+	return "logger " + name
+}
+
+// levelCore 按当前的全局级别和 logger 名称的级别过滤日志，内部的 core 不再做级别判断
+type levelCore struct {
+	zapcore.Core
+	sink     *sinkLevel
+	registry *levelRegistry
+}
+
+func newLevelCore(core zapcore.Core, sink *sinkLevel, registry *levelRegistry) zapcore.Core {
+	return &levelCore{Core: core, sink: sink, registry: registry}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	if c.sink.level.Enabled(level) {
+		return true
+	}
+	named := c.registry.named.Load()
+	return named != nil && level >= named.min
+}
+
+// Level 供 zapcore.LevelOf 使用，返回可能输出的最低级别
+func (c *levelCore) Level() zapcore.Level {
+	level := c.sink.level.Level()
+	if named := c.registry.named.Load(); named != nil && named.min < level {
+		level = named.min
+	}
+	return level
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), sink: c.sink, registry: c.registry}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	enabled := c.sink.level.Enabled(entry.Level)
+	if named := c.registry.named.Load(); named != nil && entry.LoggerName != "" {
+		if level, ok := named.levelFor(entry.LoggerName); ok {
+			enabled = entry.Level >= level
+		}
+	}
+	if enabled {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// parseSinkLevels 计算配置文件中每个输出的级别
+func parseSinkLevels(debug bool, cfg config.LogConfig) (map[string]zapcore.Level, error) {
+	consoleLevel, err := parseLevel(debug, cfg.Console.Level, cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	fileLevel, err := parseLevel(debug, cfg.File.Level, cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]zapcore.Level{SinkConsole: consoleLevel, SinkFile: fileLevel}, nil
+}
+
+func parseNamedLevels(loggers map[string]string) (map[string]zapcore.Level, error) {
+	named := map[string]zapcore.Level{}
+	for name, raw := range loggers {
+		level, err := zapcore.ParseLevel(raw)
+		if err != nil {
+			return nil, fmt.Errorf("logger %s 的日志级别不合法: %w", name, err)
+		}
+		named[name] = level
+	}
+	return named, nil
+}
+
+// LevelRequest 修改日志级别的请求，管理接口和 SIGHUP 共用。
+// Level 为空时恢复配置文件中的级别；TTL 使用 time.ParseDuration 的格式，例如 10m。
+type LevelRequest struct {
+	Name  string `json:"name"`
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// Apply 解析并执行请求，返回的错误都是参数错误
+func (r LevelRequest) Apply() error {
+	name := strings.TrimSpace(r.Name)
+	if strings.TrimSpace(r.Level) == "" {
+		ResetLevel(name)
+		Sugar().Infof("%s 已恢复为配置文件中的级别", levelTargetName(name))
+		return nil
+	}
+
+	level, err := zapcore.ParseLevel(strings.TrimSpace(r.Level))
+	if err != nil {
+		return fmt.Errorf("日志级别不合法: %w", err)
+	}
+	var ttl time.Duration
+	if strings.TrimSpace(r.TTL) != "" {
+		ttl, err = time.ParseDuration(strings.TrimSpace(r.TTL))
+		if err != nil {
+			return fmt.Errorf("ttl 不合法: %w", err)
+		}
+	}
+
+	if err := SetLevel(name, level, ttl); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		Sugar().Infof("%s 的级别已修改为 %s，%s 后恢复", levelTargetName(name), level, ttl)
+	} else {
+		Sugar().Infof("%s 的级别已修改为 %s", levelTargetName(name), level)
+	}
+	return nil
+}
+
+// LevelRequestFilePath 通过 SIGHUP 修改日志级别时使用的请求文件，和应用日志放在同一个目录
+func LevelRequestFilePath(cfg config.LogConfig) string {
+	return filepath.Join(filepath.Dir(LogFilePath(cfg)), LevelRequestFilename)
+}
+
+// WriteLevelRequest 写入请求文件，运行中的服务收到 SIGHUP 后读取并执行
+func WriteLevelRequest(cfg config.LogConfig, req LevelRequest) error {
+	filename := LevelRequestFilePath(cfg)
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return err
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0o600)
+}
+
+// TakeLevelRequest 读取并删除请求文件，文件不存在时返回 nil
+func TakeLevelRequest(cfg config.LogConfig) (*LevelRequest, error) {
+	filename := LevelRequestFilePath(cfg)
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(filename); err != nil {
+		return nil, err
+	}
+
+	req := &LevelRequest{}
+	if err := json.Unmarshal(data, req); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", filename, err)
+	}
+	return req, nil
+}
@@ -1,9 +1,10 @@
 package logging
 
 import (
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"my-web-template/internal/config"
 )
 
 // var logger zap.Logger
@@ -27,6 +29,7 @@ func Sugar() *zap.SugaredLogger {
 
 const LogDirName = "logs"
 const DefaultLogFilename = "app.log"
+const DefaultAccessLogFilename = "access.log"
 
 // GetExecPath 获取可执行文件的路径，默认将日志文件放到可执行文件同级，如果有其他需求再修改这部分代码
 func GetExecPath() string {
@@ -36,27 +39,77 @@ func GetExecPath() string {
 	return execPath
 }
 
-func InitLogger(debug bool, logFilename string) error {
-	execPath := GetExecPath()
-	logDir := path.Join(execPath, LogDirName)
-	if _, err := os.Stat(logDir); os.IsNotExist(err) {
-		if err := os.Mkdir(logDir, os.ModePerm); err != nil {
-			return err
-		}
+// LogFilePath 应用日志文件的绝对路径
+func LogFilePath(cfg config.LogConfig) string {
+	return resolvePath(cfg.File.Path, filepath.Join(LogDirName, DefaultLogFilename))
+}
+
+// AccessLogFilePath access log 文件的绝对路径，默认和应用日志放在同一个目录
+func AccessLogFilePath(cfg config.LogConfig) string {
+	return resolvePath(cfg.File.AccessPath, filepath.Join(filepath.Dir(LogFilePath(cfg)), DefaultAccessLogFilename))
+}
+
+func resolvePath(p string, fallback string) string {
+	if strings.TrimSpace(p) == "" {
+		p = fallback
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(GetExecPath(), p)
+	}
+	return p
+}
+
+// NewRotateWriter 创建按配置滚动的文件 writer，应用日志和 access log 共用同一套滚动配置
+func NewRotateWriter(cfg config.LogConfig, filename string) (io.WriteCloser, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
 	}
 
-	if logFilename == "" {
-		logFilename = DefaultLogFilename
+	maxSize, maxBackups, maxAge := cfg.File.MaxSize, cfg.File.MaxBackups, cfg.File.MaxAge
+	if maxSize <= 0 {
+		maxSize = 10
+	}
+	if maxBackups <= 0 {
+		maxBackups = 5
+	}
+	if maxAge <= 0 {
+		maxAge = 28
 	}
 
-	lumberjackLogger := &lumberjack.Logger{
-		Filename:   path.Join(logDir, logFilename),
-		MaxSize:    10,
-		MaxBackups: 5,
-		MaxAge:     28,
-		Compress:   false,
+	return &lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		Compress:   cfg.File.Compress,
+	}, nil
+}
+
+// parseLevel 依次使用 sink 的级别、log.level，都为空时 debug 模式为 debug，否则为 info
+func parseLevel(debug bool, levels ...string) (zapcore.Level, error) {
+	for _, level := range levels {
+		if level != "" {
+			return zapcore.ParseLevel(level)
+		}
+	}
+	if debug {
+		return zapcore.DebugLevel, nil
 	}
+	return zapcore.InfoLevel, nil
+}
 
+func newEncoder(format string, encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch format {
+	case "", "console":
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case "json":
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("不支持的日志格式: %s", format)
+	}
+}
+
+func InitLogger(debug bool, cfg config.LogConfig) error {
 	// 基础编码配置
 	baseEncoderConfig := zapcore.EncoderConfig{
 		TimeKey:          "time",
@@ -77,34 +130,51 @@ func InitLogger(debug bool, logFilename string) error {
 		EncodeCaller:   zapcore.ShortCallerEncoder,
 	}
 
-	// 控制台编码配置（带颜色）
+	// 控制台编码配置，console 格式下带颜色
 	consoleEncoderConfig := baseEncoderConfig
-	consoleEncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder // 控制台始终带颜色
+	if cfg.Console.Format != "json" {
+		consoleEncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
 	if debug {
 		consoleEncoderConfig.ConsoleSeparator = " " // 调试模式下使用空格分隔符
 	}
+	consoleEncoder, err := newEncoder(cfg.Console.Format, consoleEncoderConfig)
+	if err != nil {
+		return err
+	}
+	// 级别由 levelCore 判断，支持运行时修改，内部的 core 接收所有级别
+	configured, err := parseSinkLevels(debug, cfg)
+	if err != nil {
+		return err
+	}
+	named, err := parseNamedLevels(cfg.Loggers)
+	if err != nil {
+		return err
+	}
+	consoleSink := &sinkLevel{name: SinkConsole, configured: configured[SinkConsole], level: zap.NewAtomicLevelAt(configured[SinkConsole])}
+	sinks := []*sinkLevel{consoleSink}
+	cores := []zapcore.Core{
+		newLevelCore(zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel), consoleSink, levels),
+	}
 
-	// 文件编码配置（不带颜色）
-	fileEncoderConfig := baseEncoderConfig
-	fileEncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder // 文件不带颜色
-
-	// 创建编码器
-	fileEncoder := zapcore.NewConsoleEncoder(fileEncoderConfig)
-	consoleEncoder := zapcore.NewConsoleEncoder(consoleEncoderConfig)
-
-	// 创建输出目标
-	fileSyncer := zapcore.AddSync(lumberjackLogger)
-	consoleSyncer := zapcore.AddSync(os.Stdout)
-
-	// 创建核心（Core）
-	fileCore := zapcore.NewCore(fileEncoder, fileSyncer, zapcore.DebugLevel)
-	consoleCore := zapcore.NewCore(consoleEncoder, consoleSyncer, zapcore.DebugLevel)
-
-	// 合并核心
-	core := zapcore.NewTee(fileCore, consoleCore)
+	// 文件输出，stdout_only 时跳过
+	if !cfg.StdoutOnly {
+		fileEncoder, err := newEncoder(cfg.File.Format, baseEncoderConfig)
+		if err != nil {
+			return err
+		}
+		fileWriter, err := NewRotateWriter(cfg, LogFilePath(cfg))
+		if err != nil {
+			return err
+		}
+		fileSink := &sinkLevel{name: SinkFile, configured: configured[SinkFile], level: zap.NewAtomicLevelAt(configured[SinkFile])}
+		sinks = append(sinks, fileSink)
+		cores = append(cores, newLevelCore(zapcore.NewCore(fileEncoder, zapcore.AddSync(fileWriter), zapcore.DebugLevel), fileSink, levels))
+	}
+	levels.register(sinks, named)
 
-	// 创建Logger
-	logger := zap.New(core, zap.AddCaller())
+	// 合并核心，创建Logger
+	logger := zap.New(zapcore.NewTee(cores...), zap.AddCaller())
 	sugarLogger = *logger.Sugar()
 
 	return nil
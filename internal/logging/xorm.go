@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	xormLog "xorm.io/xorm/log"
+)
+
+// XormLoggerName xorm 日志使用的 logger 名称，可以在 [log.loggers] 中单独设置级别
+const XormLoggerName = "xorm"
+
+// DefaultSlowQueryThreshold 未配置 database.slow_query_threshold 时的慢查询阈值
+const DefaultSlowQueryThreshold = 200 * time.Millisecond
+
+// XormLogger 把 xorm 的日志输出到 zap。
+// show_sql 打开时每条 SQL 以 DEBUG 级别输出；无论 show_sql 是否打开，
+// 执行时间超过阈值的 SQL 都会输出一条 WARN 级别的 slow query 日志，其中绑定参数会被脱敏。
+// 如果 xorm session 使用了带有请求级 logger 的 context，日志中会带上 request_id。
+type XormLogger struct {
+	logger        *zap.SugaredLogger
+	level         xormLog.LogLevel
+	showSQL       bool
+	slowThreshold time.Duration
+}
+
+func NewXormLogger(logger *zap.SugaredLogger, slowThreshold time.Duration) *XormLogger {
+	if slowThreshold <= 0 {
+		slowThreshold = DefaultSlowQueryThreshold
+	}
+	return &XormLogger{
+		logger:        logger.Named(XormLoggerName),
+		level:         xormLog.LOG_INFO,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *XormLogger) BeforeSQL(xormLog.LogContext) {}
+
+func (l *XormLogger) AfterSQL(ctx xormLog.LogContext) {
+	logger := l.logger
+	if ctx.Ctx != nil {
+		if requestLogger := FromContext(ctx.Ctx); requestLogger != Sugar() {
+			logger = requestLogger.Named(XormLoggerName)
+		}
+	}
+
+	if ctx.ExecuteTime >= l.slowThreshold {
+		fields := []interface{}{
+			"sql", ctx.SQL,
+			"args", redactArgs(ctx.Args),
+			"duration", ctx.ExecuteTime,
+			"threshold", l.slowThreshold,
+		}
+		if ctx.Err != nil {
+			// 超时取消的查询通常也是慢查询，需要区分是执行慢还是执行失败
+			fields = append(fields, "error", ctx.Err)
+		}
+		logger.Warnw("slow query", fields...)
+		return
+	}
+
+	if l.showSQL {
+		if ctx.Err != nil {
+			logger.Debugw("sql", "sql", ctx.SQL, "args", ctx.Args, "duration", ctx.ExecuteTime, "error", ctx.Err)
+		} else {
+			logger.Debugw("sql", "sql", ctx.SQL, "args", ctx.Args, "duration", ctx.ExecuteTime)
+		}
+	}
+}
+
+// redactArgs 只保留参数的类型，字符串和字节数组额外保留长度
+func redactArgs(args []interface{}) []string {
+	redacted := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			redacted = append(redacted, "<nil>")
+		case string:
+			redacted = append(redacted, fmt.Sprintf("<string len=%d>", len(v)))
+		case []byte:
+			redacted = append(redacted, fmt.Sprintf("<[]byte len=%d>", len(v)))
+		default:
+			redacted = append(redacted, fmt.Sprintf("<%T>", v))
+		}
+	}
+	return redacted
+}
+
+func (l *XormLogger) Debugf(format string, v ...interface{}) {
+	if l.level <= xormLog.LOG_DEBUG {
+		l.logger.Debugf(format, v...)
+	}
+}
+
+func (l *XormLogger) Infof(format string, v ...interface{}) {
+	if l.level <= xormLog.LOG_INFO {
+		l.logger.Infof(format, v...)
+	}
+}
+
+func (l *XormLogger) Warnf(format string, v ...interface{}) {
+	if l.level <= xormLog.LOG_WARNING {
+		l.logger.Warnf(format, v...)
+	}
+}
+
+func (l *XormLogger) Errorf(format string, v ...interface{}) {
+	if l.level <= xormLog.LOG_ERR {
+		l.logger.Errorf(format, v...)
+	}
+}
+
+func (l *XormLogger) Level() xormLog.LogLevel {
+	return l.level
+}
+
+func (l *XormLogger) SetLevel(level xormLog.LogLevel) {
+	l.level = level
+}
+
+func (l *XormLogger) ShowSQL(show ...bool) {
+	if len(show) == 0 {
+		l.showSQL = true
+		return
+	}
+	l.showSQL = show[0]
+}
+
+// IsShowSQL 始终返回 true，xorm 只有在这里返回 true 时才会调用 AfterSQL，
+// 慢查询检测依赖 AfterSQL，是否输出普通 SQL 日志由 showSQL 决定
+func (l *XormLogger) IsShowSQL() bool {
+	return true
+}
+
+var _ xormLog.ContextLogger = (*XormLogger)(nil)
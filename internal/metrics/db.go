@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"xorm.io/xorm"
+)
+
+// RegisterDBStats 注册数据库连接池指标，数据来自 engine.DB().Stats()
+func RegisterDBStats(engine *xorm.Engine, dbName string) error {
+	return Register(collectors.NewDBStatsCollector(engine.DB().DB, dbName))
+}
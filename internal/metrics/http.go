@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"my-web-template/internal/constant"
+)
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP 请求处理时间",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	resultCodes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "result_codes_total",
+		Help:      "返回的 AppResult.code 的次数",
+	}, []string{"code"})
+
+	sessionEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "session",
+		Name:      "events_total",
+		Help:      "session 登录、退出次数",
+	}, []string{"event"})
+)
+
+const (
+	SessionEventLogin  = "login"
+	SessionEventLogout = "logout"
+)
+
+// ObserveRequest 记录一次请求，route 需要是路由模板（例如 /api/user/:id），不能是实际路径，避免 label 数量无限增长
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	httpDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveResultCode 记录一次 AppResult.code
+func ObserveResultCode(code constant.ResultCode) {
+	resultCodes.WithLabelValues(strconv.Itoa(int(code))).Inc()
+}
+
+// ObserveSessionEvent 记录一次 session 事件，event 为 SessionEventLogin 或 SessionEventLogout
+func ObserveSessionEvent(event string) {
+	sessionEvents.WithLabelValues(event).Inc()
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"my-web-template/internal/version"
+)
+
+// Namespace 所有内置指标名称的前缀
+const Namespace = "app"
+
+// DefaultPath 未配置 metrics.path 时的路由
+const DefaultPath = "/metrics"
+
+// registry 不使用 prometheus 的默认 registry，避免第三方库注册的指标混进来
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newBuildInfoCollector(),
+		httpRequests,
+		httpDuration,
+		resultCodes,
+		sessionEvents,
+	)
+}
+
+// Registry 返回应用使用的 registry
+func Registry() *prometheus.Registry {
+	return registry
+}
+
+// Register 注册自定义指标，service 等模块可以在初始化时注册自己的指标，名称建议以 Namespace 开头
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector)
+}
+
+// MustRegister 和 Register 相同，注册失败时 panic，适合在包初始化时使用
+func MustRegister(collectors ...prometheus.Collector) {
+	registry.MustRegister(collectors...)
+}
+
+// Handler 以 Prometheus 文本格式输出所有指标
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// newBuildInfoCollector 构建信息，值固定为 1，信息放在 label 中
+func newBuildInfoCollector() prometheus.Collector {
+	info := version.Info()
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "build_info",
+		Help:      "应用的构建信息，值固定为 1",
+		ConstLabels: prometheus.Labels{
+			"app_name":   info.AppName,
+			"version":    info.AppVersion,
+			"git_commit": info.GitCommit,
+			"built_at":   info.BuiltAt,
+			"go_version": info.GoVersion,
+		},
+	})
+	buildInfo.Set(1)
+	return buildInfo
+}
@@ -0,0 +1,65 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"xorm.io/xorm"
+)
+
+// MigrateFunc 执行一次迁移（或回滚），session 已经开启了事务
+type MigrateFunc func(session *xorm.Session) error
+
+// Migration 一个带版本号的迁移，版本号越小越先执行。
+// Down 为 nil 时表示该迁移不支持回滚。
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      MigrateFunc
+	Down    MigrateFunc
+}
+
+// goMigrations 使用 Go 代码编写的迁移，新增迁移时追加到这里
+var goMigrations = []Migration{
+	migrationV1InitSchema,
+}
+
+// Load 合并 Go 迁移和当前数据库驱动对应的 SQL 迁移，按版本号排序返回
+func Load(driver string) ([]Migration, error) {
+	sqlMigrations, err := loadSQLMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(goMigrations)+len(sqlMigrations))
+	migrations = append(migrations, goMigrations...)
+	migrations = append(migrations, sqlMigrations...)
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		switch {
+		case a.Version < b.Version:
+			return -1
+		case a.Version > b.Version:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	for i, m := range migrations {
+		if m.Version == 0 {
+			return nil, fmt.Errorf("迁移 %s 的版本号不能为 0", m.Name)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少 Up", m.Version, m.Name)
+		}
+		if i > 0 && migrations[i-1].Version == m.Version {
+			return nil, fmt.Errorf("迁移版本号重复: %d (%s, %s)", m.Version, migrations[i-1].Name, m.Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// ErrIrreversible 回滚一个没有 Down 的迁移
+var ErrIrreversible = errors.New("migration is irreversible")
@@ -0,0 +1,301 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"xorm.io/xorm"
+)
+
+// DefaultLockTimeout 等待其他实例释放迁移锁的最长时间
+const DefaultLockTimeout = time.Minute
+
+const (
+	// lockRefreshInterval 持有锁期间刷新 locked_at 的间隔
+	lockRefreshInterval = 10 * time.Second
+	// lockStaleAfter locked_at 超过这个时间没有刷新时，认为持有锁的实例已经异常退出，其他实例可以接管
+	lockStaleAfter = 6 * lockRefreshInterval
+)
+
+// schemaMigration 记录已经执行过的迁移
+type schemaMigration struct {
+	Version   uint64 `xorm:"UNSIGNED BIGINT NOTNULL PK"`
+	Name      string `xorm:"VARCHAR(255) NOTNULL"`
+	AppliedAt int64  `xorm:"BIGINT NOTNULL"`
+}
+
+func (*schemaMigration) TableName() string { return "schema_migrations" }
+
+// schemaMigrationLock 迁移锁，表中最多只有一行，插入成功即获得锁。
+// 不依赖具体数据库的锁实现，sqlite3、mysql、postgres 都可以使用。
+// 持有锁的实例会定期刷新 LockedAt，长时间没有刷新的锁会被其他实例接管。
+type schemaMigrationLock struct {
+	ID       int64  `xorm:"BIGINT NOTNULL PK"`
+	Owner    string `xorm:"VARCHAR(255) NOTNULL"`
+	LockedAt int64  `xorm:"BIGINT NOTNULL"`
+}
+
+func (*schemaMigrationLock) TableName() string { return "schema_migrations_lock" }
+
+const lockRowId = 1
+
+// Status 单个迁移的执行状态
+type Status struct {
+	Version   uint64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	engine      *xorm.Engine
+	migrations  []Migration
+	logger      *zap.SugaredLogger
+	lockTimeout time.Duration
+}
+
+// NewMigrator 加载 engine 对应驱动的所有迁移，并确保迁移记录表存在
+func NewMigrator(engine *xorm.Engine, logger *zap.SugaredLogger) (*Migrator, error) {
+	migrations, err := Load(engine.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkServerVersion(engine); err != nil {
+		return nil, err
+	}
+	if err := engine.Sync(new(schemaMigration), new(schemaMigrationLock)); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+
+	return &Migrator{
+		engine:      engine,
+		migrations:  migrations,
+		logger:      logger,
+		lockTimeout: DefaultLockTimeout,
+	}, nil
+}
+
+// Status 返回所有迁移及其执行状态，按版本号排序
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = time.UnixMilli(record.AppliedAt)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending 返回还没有执行的迁移
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0)
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up 按版本号顺序执行所有未执行的迁移，返回本次执行的迁移数量
+func (m *Migrator) Up() (int, error) {
+	count := 0
+	err := m.withLock(func() error {
+		pending, err := m.Pending()
+		if err != nil {
+			return err
+		}
+		for _, migration := range pending {
+			m.logger.Infof("执行迁移 %d_%s", migration.Version, migration.Name)
+			if err := m.apply(migration); err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down 按版本号倒序回滚最近执行的 steps 个迁移，返回实际回滚的数量
+func (m *Migrator) Down(steps int) (int, error) {
+	count := 0
+	err := m.withLock(func() error {
+		applied, err := m.appliedMigrations()
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败: %w", migration.Version, migration.Name, ErrIrreversible)
+			}
+			m.logger.Infof("回滚迁移 %d_%s", migration.Version, migration.Name)
+			if err := m.revert(migration); err != nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败: %w", migration.Version, migration.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// ForceUnlock 强制释放迁移锁，仅在持有锁的实例异常退出后使用
+func (m *Migrator) ForceUnlock() error {
+	_, err := m.engine.ID(lockRowId).Delete(new(schemaMigrationLock))
+	return err
+}
+
+func (m *Migrator) apply(migration Migration) error {
+	return m.inTransaction(func(session *xorm.Session) error {
+		if err := migration.Up(session); err != nil {
+			return err
+		}
+		_, err := session.Insert(&schemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now().UnixMilli(),
+		})
+		return err
+	})
+}
+
+func (m *Migrator) revert(migration Migration) error {
+	return m.inTransaction(func(session *xorm.Session) error {
+		if err := migration.Down(session); err != nil {
+			return err
+		}
+		_, err := session.ID(migration.Version).Delete(new(schemaMigration))
+		return err
+	})
+}
+
+// inTransaction 在事务中执行 fn。注意 MySQL 的 DDL 会隐式提交，无法随事务回滚，
+// 中途失败时迁移记录不会写入，下次会从头重新执行，所以 MySQL 迁移中的每条语句都需要可以重复执行：
+// 一条 ALTER TABLE 中的多个修改是原子的，ADD/DROP COLUMN 需要先检查 information_schema。
+func (m *Migrator) inTransaction(fn func(session *xorm.Session) error) error {
+	session := m.engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if err := fn(session); err != nil {
+		_ = session.Rollback()
+		return err
+	}
+	return session.Commit()
+}
+
+func (m *Migrator) appliedMigrations() (map[uint64]schemaMigration, error) {
+	records := make([]schemaMigration, 0)
+	if err := m.engine.Find(&records); err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	applied := make(map[uint64]schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// withLock 获取迁移锁后执行 fn，锁被其他实例持有时每秒重试一次，直到超时。
+// 持有锁期间在后台刷新 locked_at；持有者超过 lockStaleAfter 没有刷新时接管它的锁。
+func (m *Migrator) withLock(fn func() error) error {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d", hostname, os.Getpid())
+	deadline := time.Now().Add(m.lockTimeout)
+
+	for {
+		_, err := m.engine.Insert(&schemaMigrationLock{ID: lockRowId, Owner: owner, LockedAt: time.Now().UnixMilli()})
+		if err == nil {
+			break
+		}
+
+		holder := &schemaMigrationLock{}
+		exists, getErr := m.engine.ID(lockRowId).Get(holder)
+		if getErr != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", getErr)
+		}
+		if !exists {
+			// 不是因为锁被占用导致的插入失败
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if time.Since(time.UnixMilli(holder.LockedAt)) > lockStaleAfter {
+			// 只删除读到的这把锁，其他实例同时接管时只有一个能删除成功
+			taken, err := m.engine.Where("id = ? AND owner = ? AND locked_at = ?", lockRowId, holder.Owner, holder.LockedAt).
+				Delete(new(schemaMigrationLock))
+			if err != nil {
+				return fmt.Errorf("接管迁移锁失败: %w", err)
+			}
+			if taken > 0 {
+				m.logger.Warnf("迁移锁由 %s 持有，但从 %s 起没有刷新，已接管",
+					holder.Owner, time.UnixMilli(holder.LockedAt).Format(time.RFC3339))
+			}
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf(
+				"等待迁移锁超时，锁由 %s 于 %s 持有，如果该实例已经退出，可以强制释放锁",
+				holder.Owner, time.UnixMilli(holder.LockedAt).Format(time.RFC3339),
+			)
+		}
+		m.logger.Infof("迁移锁由 %s 持有，等待释放", holder.Owner)
+		time.Sleep(time.Second)
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go m.refreshLock(owner, done, stopped)
+
+	defer func() {
+		close(done)
+		<-stopped
+		if _, err := m.engine.Where("id = ? AND owner = ?", lockRowId, owner).Delete(new(schemaMigrationLock)); err != nil {
+			m.logger.Errorf("释放迁移锁失败: %v", err)
+		}
+	}()
+	return fn()
+}
+
+// refreshLock 定期刷新锁的 locked_at，直到 done 被关闭
+func (m *Migrator) refreshLock(owner string, done <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(lockRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			affected, err := m.engine.Where("id = ? AND owner = ?", lockRowId, owner).
+				Cols("locked_at").Update(&schemaMigrationLock{LockedAt: time.Now().UnixMilli()})
+			if err != nil {
+				m.logger.Warnf("刷新迁移锁失败: %v", err)
+			} else if affected == 0 {
+				m.logger.Errorf("迁移锁已被其他实例接管或强制释放")
+			}
+		}
+	}
+}
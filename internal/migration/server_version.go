@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"xorm.io/xorm"
+)
+
+// minMySQLVersion 迁移 0002、0004 使用了函数索引，需要 MySQL 8.0.13 及以上版本，MariaDB 不支持
+var minMySQLVersion = [3]int{8, 0, 13}
+
+// checkServerVersion 检查数据库版本是否满足迁移的要求，目前只有 MySQL 有版本要求
+func checkServerVersion(engine *xorm.Engine) error {
+	if engine.DriverName() != "mysql" {
+		return nil
+	}
+
+	var version string
+	if _, err := engine.SQL("SELECT VERSION()").Get(&version); err != nil {
+		return fmt.Errorf("获取 MySQL 版本失败: %w", err)
+	}
+	if !mysqlVersionSupported(version) {
+		return fmt.Errorf("迁移需要 MySQL %d.%d.%d 及以上版本，当前版本: %s",
+			minMySQLVersion[0], minMySQLVersion[1], minMySQLVersion[2], version)
+	}
+	return nil
+}
+
+// mysqlVersionSupported version 形如 8.0.36 或 8.0.36-log
+func mysqlVersionSupported(version string) bool {
+	if strings.Contains(strings.ToLower(version), "mariadb") {
+		return false
+	}
+
+	number, _, _ := strings.Cut(version, "-")
+	parts := strings.Split(number, ".")
+	for i, minPart := range minMySQLVersion {
+		if i >= len(parts) {
+			return false
+		}
+		part, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return false
+		}
+		if part != minPart {
+			return part > minPart
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"xorm.io/xorm"
+)
+
+// sqlFS SQL 迁移文件，按数据库驱动分目录存放：
+// sql/<driver>/<version>_<name>.up.sql 和 sql/<driver>/<version>_<name>.down.sql
+//
+//go:embed sql
+var sqlFS embed.FS
+
+// loadSQLMigrations 读取当前驱动目录下的 SQL 迁移
+func loadSQLMigrations(driver string) ([]Migration, error) {
+	dir := path.Join("sql", driver)
+	entries, err := fs.ReadDir(sqlFS, dir)
+	if err != nil {
+		// 没有这个驱动的目录，说明没有 SQL 迁移
+		return nil, nil
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	versions := make([]uint64, 0)
+	for _, entry := range entries {
+		filename := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(filename, ".sql") {
+			continue
+		}
+
+		var direction string
+		base := strings.TrimSuffix(filename, ".sql")
+		switch {
+		case strings.HasSuffix(base, ".up"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("SQL 迁移文件名不合法: %s，需要以 .up.sql 或 .down.sql 结尾", filename)
+		}
+		base = strings.TrimSuffix(base, "."+direction)
+
+		versionPart, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("SQL 迁移文件名不合法: %s，需要形如 0001_name.up.sql", filename)
+		}
+		version, err := strconv.ParseUint(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SQL 迁移文件名不合法: %s，版本号需要是数字", filename)
+		}
+
+		content, err := fs.ReadFile(sqlFS, path.Join(dir, filename))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+			versions = append(versions, version)
+		}
+		if direction == "up" {
+			m.Up = execSQL(string(content))
+		} else {
+			m.Down = execSQL(string(content))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		migrations = append(migrations, *byVersion[version])
+	}
+	return migrations, nil
+}
+
+// execSQL 依次执行文件中的语句，语句之间以行尾的分号分隔
+func execSQL(content string) MigrateFunc {
+	return func(session *xorm.Session) error {
+		for _, statement := range splitStatements(content) {
+			if _, err := session.Exec(statement); err != nil {
+				return fmt.Errorf("执行 SQL 失败: %w\n%s", err, statement)
+			}
+		}
+		return nil
+	}
+}
+
+func splitStatements(content string) []string {
+	statements := make([]string, 0)
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1 @@
+DROP INDEX idx_app_user_email ON app_user;
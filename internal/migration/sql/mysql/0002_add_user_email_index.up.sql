@@ -0,0 +1,2 @@
+-- 登录时通过 LOWER(email) 查找用户，函数索引需要 MySQL 8.0.13 及以上版本
+CREATE INDEX idx_app_user_email ON app_user ((LOWER(email)));
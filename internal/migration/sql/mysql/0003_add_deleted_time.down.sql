@@ -0,0 +1,58 @@
+-- MySQL 的 DDL 会隐式提交，迁移中途失败时前面的语句不会回滚，也不会写入迁移记录，
+-- 所以这里的每条语句都需要可以重复执行。MySQL 不支持 DROP COLUMN IF EXISTS，
+-- 先查询 information_schema 再决定是否执行。修改索引的 ALTER TABLE 是单条语句，本身可以重复执行。
+
+-- 存在同名的已删除记录时，恢复唯一索引会失败，需要先清理这些记录
+ALTER TABLE app_user DROP INDEX UQE_app_user_username, ADD UNIQUE INDEX UQE_app_user_username (username);
+ALTER TABLE app_role DROP INDEX UQE_app_role_name, ADD UNIQUE INDEX UQE_app_role_name (name);
+ALTER TABLE app_permission DROP INDEX UQE_app_permission_code, ADD UNIQUE INDEX UQE_app_permission_code (code);
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_user' AND COLUMN_NAME = 'deleted_time') > 0,
+    'ALTER TABLE app_user DROP COLUMN deleted_time',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_role' AND COLUMN_NAME = 'deleted_time') > 0,
+    'ALTER TABLE app_role DROP COLUMN deleted_time',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_permission' AND COLUMN_NAME = 'deleted_time') > 0,
+    'ALTER TABLE app_permission DROP COLUMN deleted_time',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_role_permission' AND COLUMN_NAME = 'deleted_time') > 0,
+    'ALTER TABLE app_role_permission DROP COLUMN deleted_time',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_user_role' AND COLUMN_NAME = 'deleted_time') > 0,
+    'ALTER TABLE app_user_role DROP COLUMN deleted_time',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
@@ -0,0 +1,65 @@
+-- MySQL 的 DDL 会隐式提交，迁移中途失败时前面的语句不会回滚，也不会写入迁移记录，
+-- 所以这里的每条语句都需要可以重复执行。MySQL 不支持 ADD/DROP COLUMN IF EXISTS，
+-- 先查询 information_schema 再决定是否执行。修改索引的 ALTER TABLE 是单条语句，本身可以重复执行。
+
+-- 软删除记录删除时间（毫秒），0 表示未删除
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_user' AND COLUMN_NAME = 'deleted_time') = 0,
+    'ALTER TABLE app_user ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_role' AND COLUMN_NAME = 'deleted_time') = 0,
+    'ALTER TABLE app_role ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_permission' AND COLUMN_NAME = 'deleted_time') = 0,
+    'ALTER TABLE app_permission ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_role_permission' AND COLUMN_NAME = 'deleted_time') = 0,
+    'ALTER TABLE app_role_permission ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+SET @ddl = IF(
+    (SELECT COUNT(*) FROM information_schema.COLUMNS
+     WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'app_user_role' AND COLUMN_NAME = 'deleted_time') = 0,
+    'ALTER TABLE app_user_role ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0',
+    'DO 0'
+);
+PREPARE ddl FROM @ddl;
+EXECUTE ddl;
+DEALLOCATE PREPARE ddl;
+
+UPDATE app_user SET deleted_time = updated_time WHERE deleted = 1;
+UPDATE app_role SET deleted_time = updated_time WHERE deleted = 1;
+UPDATE app_permission SET deleted_time = updated_time WHERE deleted = 1;
+UPDATE app_role_permission SET deleted_time = updated_time WHERE deleted = 1;
+UPDATE app_user_role SET deleted_time = updated_time WHERE deleted = 1;
+
+-- 唯一索引加上 deleted_time，软删除之后可以再创建同名的记录
+ALTER TABLE app_user DROP INDEX UQE_app_user_username, ADD UNIQUE INDEX UQE_app_user_username (username, deleted_time);
+ALTER TABLE app_role DROP INDEX UQE_app_role_name, ADD UNIQUE INDEX UQE_app_role_name (name, deleted_time);
+ALTER TABLE app_permission DROP INDEX UQE_app_permission_code, ADD UNIQUE INDEX UQE_app_permission_code (code, deleted_time);
@@ -0,0 +1,4 @@
+ALTER TABLE app_user
+    ADD INDEX idx_app_user_email ((LOWER(email))),
+    DROP INDEX UQE_app_user_lower_email,
+    DROP INDEX UQE_app_user_lower_username;
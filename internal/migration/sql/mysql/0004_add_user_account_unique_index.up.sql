@@ -0,0 +1,6 @@
+-- 用户名和邮箱不区分大小写唯一，已存在只有大小写不同的未删除用户时需要先处理这些记录
+-- 函数索引需要 MySQL 8.0.13 及以上版本
+ALTER TABLE app_user
+    ADD UNIQUE INDEX UQE_app_user_lower_username ((LOWER(username)), deleted_time),
+    ADD UNIQUE INDEX UQE_app_user_lower_email ((LOWER(email)), deleted_time),
+    DROP INDEX idx_app_user_email;
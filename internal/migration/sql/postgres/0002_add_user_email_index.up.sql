@@ -0,0 +1,2 @@
+-- 登录时通过 LOWER(email) 查找用户
+CREATE INDEX idx_app_user_email ON app_user (LOWER(email));
@@ -0,0 +1,13 @@
+-- 存在同名的已删除记录时，恢复唯一索引会失败，需要先清理这些记录
+DROP INDEX "UQE_app_user_username";
+CREATE UNIQUE INDEX "UQE_app_user_username" ON app_user (username);
+DROP INDEX "UQE_app_role_name";
+CREATE UNIQUE INDEX "UQE_app_role_name" ON app_role (name);
+DROP INDEX "UQE_app_permission_code";
+CREATE UNIQUE INDEX "UQE_app_permission_code" ON app_permission (code);
+
+ALTER TABLE app_user DROP COLUMN deleted_time;
+ALTER TABLE app_role DROP COLUMN deleted_time;
+ALTER TABLE app_permission DROP COLUMN deleted_time;
+ALTER TABLE app_role_permission DROP COLUMN deleted_time;
+ALTER TABLE app_user_role DROP COLUMN deleted_time;
@@ -0,0 +1,19 @@
+-- 软删除记录删除时间（毫秒），0 表示未删除
+ALTER TABLE app_user ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE app_role ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE app_permission ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE app_role_permission ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE app_user_role ADD COLUMN deleted_time BIGINT NOT NULL DEFAULT 0;
+UPDATE app_user SET deleted_time = updated_time WHERE deleted = true;
+UPDATE app_role SET deleted_time = updated_time WHERE deleted = true;
+UPDATE app_permission SET deleted_time = updated_time WHERE deleted = true;
+UPDATE app_role_permission SET deleted_time = updated_time WHERE deleted = true;
+UPDATE app_user_role SET deleted_time = updated_time WHERE deleted = true;
+
+-- 唯一索引加上 deleted_time，软删除之后可以再创建同名的记录
+DROP INDEX "UQE_app_user_username";
+CREATE UNIQUE INDEX "UQE_app_user_username" ON app_user (username, deleted_time);
+DROP INDEX "UQE_app_role_name";
+CREATE UNIQUE INDEX "UQE_app_role_name" ON app_role (name, deleted_time);
+DROP INDEX "UQE_app_permission_code";
+CREATE UNIQUE INDEX "UQE_app_permission_code" ON app_permission (code, deleted_time);
@@ -0,0 +1,3 @@
+CREATE INDEX idx_app_user_email ON app_user (LOWER(email));
+DROP INDEX `UQE_app_user_lower_email`;
+DROP INDEX `UQE_app_user_lower_username`;
@@ -0,0 +1,5 @@
+-- 用户名和邮箱不区分大小写唯一，已存在只有大小写不同的未删除用户时需要先处理这些记录
+CREATE UNIQUE INDEX `UQE_app_user_lower_username` ON app_user (LOWER(username), deleted_time);
+CREATE UNIQUE INDEX `UQE_app_user_lower_email` ON app_user (LOWER(email), deleted_time);
+-- 登录时的 LOWER(email) 查询由新的唯一索引覆盖
+DROP INDEX `idx_app_user_email`;
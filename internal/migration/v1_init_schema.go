@@ -0,0 +1,85 @@
+package migration
+
+import "xorm.io/xorm"
+
+// 下面的结构体是第一个版本表结构的快照，之后修改 model 不应该影响这个迁移的结果，
+// 所以这里不直接引用 model 包中的结构体。
+// 使用 Sync 建表，已经通过旧版本 engine.Sync 创建过表的数据库也可以安全执行。
+// xorm 会忽略未导出的字段，所以 v1BaseModel 通过导出的 Base 字段引用，而不是匿名嵌入。
+
+type v1BaseModel struct {
+	ID          uint64 `xorm:"UNSIGNED BIGINT NOTNULL PK AUTOINCR"`
+	CreatedTime int64  `xorm:"UNSIGNED BIGINT NOTNULL"`
+	UpdatedTime int64  `xorm:"UNSIGNED BIGINT NOTNULL"`
+	Deleted     bool   `xorm:"BOOL NOTNULL DEFAULT false"`
+}
+
+type v1AppUser struct {
+	Base     v1BaseModel `xorm:"extends"`
+	Username string      `xorm:"VARCHAR(255) NOT NULL UNIQUE"`
+	Password string      `xorm:"VARCHAR(255) NOT NULL"`
+	Email    string      `xorm:"VARCHAR(255) NOT NULL"`
+	State    uint8       `xorm:"TINYINT NOTNULL DEFAULT 0"`
+}
+
+func (*v1AppUser) TableName() string { return "app_user" }
+
+type v1AppRole struct {
+	Base        v1BaseModel `xorm:"extends"`
+	Name        string      `xorm:"VARCHAR(64) NOT NULL UNIQUE"`
+	Description string      `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+}
+
+func (*v1AppRole) TableName() string { return "app_role" }
+
+type v1AppPermission struct {
+	Base        v1BaseModel `xorm:"extends"`
+	Code        string      `xorm:"VARCHAR(128) NOT NULL UNIQUE"`
+	Description string      `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+}
+
+func (*v1AppPermission) TableName() string { return "app_permission" }
+
+type v1AppRolePermission struct {
+	Base         v1BaseModel `xorm:"extends"`
+	RoleId       uint64      `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(role_permission) INDEX"`
+	PermissionId uint64      `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(role_permission)"`
+}
+
+func (*v1AppRolePermission) TableName() string { return "app_role_permission" }
+
+type v1AppUserRole struct {
+	Base   v1BaseModel `xorm:"extends"`
+	UserId uint64      `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(user_role) INDEX"`
+	RoleId uint64      `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(user_role) INDEX"`
+}
+
+func (*v1AppUserRole) TableName() string { return "app_user_role" }
+
+var migrationV1InitSchema = Migration{
+	Version: 1,
+	Name:    "init_schema",
+	Up: func(session *xorm.Session) error {
+		return session.Sync(
+			new(v1AppUser),
+			new(v1AppRole),
+			new(v1AppPermission),
+			new(v1AppRolePermission),
+			new(v1AppUserRole),
+		)
+	},
+	Down: func(session *xorm.Session) error {
+		for _, bean := range []any{
+			new(v1AppUserRole),
+			new(v1AppRolePermission),
+			new(v1AppPermission),
+			new(v1AppRole),
+			new(v1AppUser),
+		} {
+			if err := session.DropTable(bean); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
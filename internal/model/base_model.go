@@ -8,11 +8,15 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// BaseModel 所有表共用的字段。
+// 软删除以 DeletedTime 为准，0 表示未删除；Deleted 和 DeletedTime 由 repository 同时维护，不要直接修改。
+// 需要唯一的字段应该和 deleted_time 一起建唯一索引，这样软删除之后还可以创建同名的记录。
 type BaseModel struct {
 	ID          uint64 `xorm:"UNSIGNED BIGINT NOTNULL PK AUTOINCR"`
 	CreatedTime int64  `xorm:"UNSIGNED BIGINT NOTNULL"`
 	UpdatedTime int64  `xorm:"UNSIGNED BIGINT NOTNULL"`
 	Deleted     bool   `xorm:"BOOL NOTNULL DEFAULT false"`
+	DeletedTime int64  `xorm:"BIGINT NOTNULL DEFAULT 0"` // 删除时间，单位毫秒
 }
 
 // BeforeInsert 插入之前
@@ -26,3 +30,13 @@ func (b *BaseModel) BeforeInsert() {
 func (b *BaseModel) BeforeUpdate() {
 	b.UpdatedTime = time.Now().UnixMilli()
 }
+
+// IsDeleted 是否已经被软删除
+func (b *BaseModel) IsDeleted() bool {
+	return b.DeletedTime > 0
+}
+
+// Base 返回嵌入的 BaseModel，嵌入了 BaseModel 的模型都会有这个方法，BaseRepository 通过它访问公共字段
+func (b *BaseModel) Base() *BaseModel {
+	return b
+}
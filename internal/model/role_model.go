@@ -0,0 +1,55 @@
+package model
+
+import "my-web-template/internal/entity/vo"
+
+// AppRoleModel 角色
+type AppRoleModel struct {
+	BaseModel   `xorm:"extends"`
+	Name        string `xorm:"VARCHAR(64) NOT NULL"` // 唯一索引 (name, deleted_time)，见迁移 0003
+	Description string `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+}
+
+func (r *AppRoleModel) TableName() string {
+	return "app_role"
+}
+
+func (r *AppRoleModel) ToVO() *vo.RoleVO {
+	return &vo.RoleVO{
+		RoleId:      r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+	}
+}
+
+// AppPermissionModel 权限，Code 形如 user:read
+type AppPermissionModel struct {
+	BaseModel   `xorm:"extends"`
+	Code        string `xorm:"VARCHAR(128) NOT NULL"` // 唯一索引 (code, deleted_time)，见迁移 0003
+	Description string `xorm:"VARCHAR(255) NOT NULL DEFAULT ''"`
+}
+
+func (p *AppPermissionModel) TableName() string {
+	return "app_permission"
+}
+
+// AppRolePermissionModel 角色和权限的关联关系
+type AppRolePermissionModel struct {
+	BaseModel    `xorm:"extends"`
+	RoleId       uint64 `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(role_permission) INDEX"`
+	PermissionId uint64 `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(role_permission)"`
+}
+
+func (r *AppRolePermissionModel) TableName() string {
+	return "app_role_permission"
+}
+
+// AppUserRoleModel 用户和角色的关联关系
+type AppUserRoleModel struct {
+	BaseModel `xorm:"extends"`
+	UserId    uint64 `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(user_role) INDEX"`
+	RoleId    uint64 `xorm:"UNSIGNED BIGINT NOTNULL UNIQUE(user_role) INDEX"`
+}
+
+func (u *AppUserRoleModel) TableName() string {
+	return "app_user_role"
+}
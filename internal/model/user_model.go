@@ -4,9 +4,9 @@ import "my-web-template/internal/entity/vo"
 
 type AppUserModel struct {
 	BaseModel `xorm:"extends"`
-	Username  string `xorm:"VARCHAR(255) NOT NULL UNIQUE"`
+	Username  string `xorm:"VARCHAR(255) NOT NULL"` // 唯一索引 (LOWER(username), deleted_time)，见迁移 0004
 	Password  string `xorm:"VARCHAR(255) NOT NULL"`
-	Email     string `xorm:"VARCHAR(255) NOT NULL"`
+	Email     string `xorm:"VARCHAR(255) NOT NULL"` // 唯一索引 (LOWER(email), deleted_time)，见迁移 0004
 	State     uint8  `xorm:"TINYINT NOTNULL DEFAULT 0"`
 }
 
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
+	"my-web-template/internal/model"
+	"my-web-template/internal/result"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Entity BaseRepository 可以使用的模型，PT 是 *T，需要嵌入 model.BaseModel
+type Entity[T any] interface {
+	*T
+	Base() *model.BaseModel
+}
+
+// Scope 查询时如何处理软删除的记录
+type Scope int
+
+const (
+	ScopeActive      Scope = iota // 默认，只查询未删除的记录
+	ScopeWithTrashed              // 包含已删除的记录
+	ScopeOnlyTrashed              // 只查询已删除的记录
+)
+
+// BaseRepository 基于 model.BaseModel 的通用 CRUD。
+// 查询默认排除已删除（deleted_time > 0）的记录，WithTrashed、OnlyTrashed 返回使用其他 Scope 的副本；
+// 数据库错误统一转换为 CodeDBError 的 AppError；
+// 查询不到记录时返回 nil, nil，由 service 决定是否需要返回 CodeRecordNotFound。
+//
+// 具体的 repository 可以嵌入或持有一个 BaseRepository，只需要实现特有的查询：
+//
+//	users := repository.NewBaseRepository[model.AppUserModel](db)
+//	user, err := users.GetBy(ctx, builder.Eq{"username": "admin"})
+type BaseRepository[T any, PT Entity[T]] struct {
+	db    *xorm.Engine
+	scope Scope
+}
+
+func NewBaseRepository[T any, PT Entity[T]](db *xorm.Engine) *BaseRepository[T, PT] {
+	return &BaseRepository[T, PT]{db: db}
+}
+
+// session 创建绑定了 ctx 的 session；ctx 处于 TxManager 开启的事务中时使用事务的 session
+func (r *BaseRepository[T, PT]) session(ctx context.Context) *xorm.Session {
+	return dbSession(r.db, ctx)
+}
+
+// WithTrashed 返回包含已删除记录的 repository，例如 users.WithTrashed().GetByID(ctx, id)
+func (r *BaseRepository[T, PT]) WithTrashed() *BaseRepository[T, PT] {
+	return &BaseRepository[T, PT]{db: r.db, scope: ScopeWithTrashed}
+}
+
+// OnlyTrashed 返回只查询已删除记录的 repository
+func (r *BaseRepository[T, PT]) OnlyTrashed() *BaseRepository[T, PT] {
+	return &BaseRepository[T, PT]{db: r.db, scope: ScopeOnlyTrashed}
+}
+
+// scopeCond 当前 Scope 对应的软删除条件
+func (r *BaseRepository[T, PT]) scopeCond() builder.Cond {
+	switch r.scope {
+	case ScopeWithTrashed:
+		return builder.NewCond()
+	case ScopeOnlyTrashed:
+		return builder.Gt{"deleted_time": 0}
+	default:
+		return builder.Eq{"deleted_time": 0}
+	}
+}
+
+// query 创建按当前 Scope 过滤软删除记录的查询，cond 为 nil 时不添加额外条件
+func (r *BaseRepository[T, PT]) query(ctx context.Context, cond builder.Cond) *xorm.Session {
+	session := r.session(ctx).Where(r.scopeCond())
+	if cond != nil {
+		session = session.And(cond)
+	}
+	return session
+}
+
+func (r *BaseRepository[T, PT]) GetByID(ctx context.Context, id uint64) (*T, result.AppError) {
+	return r.GetBy(ctx, builder.Eq{"id": id})
+}
+
+// GetBy 返回第一条满足条件的记录
+func (r *BaseRepository[T, PT]) GetBy(ctx context.Context, cond builder.Cond) (*T, result.AppError) {
+	entity := new(T)
+	exists, err := r.query(ctx, cond).Get(entity)
+	if err != nil {
+		return nil, dbError(err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	return entity, nil
+}
+
+// FindBy 返回所有满足条件的记录，按 id 升序
+func (r *BaseRepository[T, PT]) FindBy(ctx context.Context, cond builder.Cond) ([]*T, result.AppError) {
+	entities := make([]*T, 0)
+	if err := r.query(ctx, cond).Asc("id").Find(&entities); err != nil {
+		return nil, dbError(err)
+	}
+
+	return entities, nil
+}
+
+// FindPage 按 cond 和 page 中的过滤条件分页查询，返回当前页的记录和满足条件的总数。
+// page 需要通过 request.ParsePageRequest 解析，其中的字段名都已经过白名单校验。
+func (r *BaseRepository[T, PT]) FindPage(ctx context.Context, cond builder.Cond, page *request.PageRequest) ([]*T, int64, result.AppError) {
+	if err := checkPageColumns(page); err != nil {
+		return nil, 0, err
+	}
+
+	session := r.query(ctx, cond).And(pageCond(page))
+	for _, sort := range page.Sorts {
+		if sort.Desc {
+			session = session.Desc(sort.Column)
+		} else {
+			session = session.Asc(sort.Column)
+		}
+	}
+
+	entities := make([]*T, 0)
+	total, err := session.Limit(page.Size, page.Offset()).FindAndCount(&entities)
+	if err != nil {
+		return nil, 0, dbError(err)
+	}
+
+	return entities, total, nil
+}
+
+// columnPattern 排序和过滤字段会直接拼接到 SQL 中，只允许普通的列名
+var columnPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// checkPageColumns 再检查一次 page 中的字段名，防止没有经过 ParsePageRequest 的 page 被传进来
+func checkPageColumns(page *request.PageRequest) result.AppError {
+	for _, sort := range page.Sorts {
+		if !columnPattern.MatchString(sort.Column) {
+			return result.NewAppError(constant.CodeRuntimeError, fmt.Sprintf("invalid sort column %q", sort.Column), true)
+		}
+	}
+	for _, filter := range page.Filters {
+		if !columnPattern.MatchString(filter.Column) {
+			return result.NewAppError(constant.CodeRuntimeError, fmt.Sprintf("invalid filter column %q", filter.Column), true)
+		}
+	}
+	return nil
+}
+
+// pageCond 把过滤条件转换为查询条件，多个条件之间是 AND
+func pageCond(page *request.PageRequest) builder.Cond {
+	cond := builder.NewCond()
+	for _, filter := range page.Filters {
+		switch filter.Op {
+		case request.FilterEq:
+			cond = cond.And(builder.Eq{filter.Column: filter.Values[0]})
+		case request.FilterLike:
+			cond = cond.And(builder.Expr(filter.Column+" LIKE ? ESCAPE '!'", "%"+escapeLike(fmt.Sprint(filter.Values[0]))+"%"))
+		case request.FilterIn:
+			cond = cond.And(builder.In(filter.Column, filter.Values...))
+		case request.FilterRange:
+			if filter.Values[0] != nil {
+				cond = cond.And(builder.Gte{filter.Column: filter.Values[0]})
+			}
+			if filter.Values[1] != nil {
+				cond = cond.And(builder.Lte{filter.Column: filter.Values[1]})
+			}
+		}
+	}
+	return cond
+}
+
+// likeEscaper 转义 LIKE 的通配符，配合 ESCAPE '!' 使用。
+// 不用反斜杠是因为 MySQL 的字符串字面量会转义反斜杠，ESCAPE 子句在各个数据库中的写法不一致
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// escapeLike 让用户输入的 % 和 _ 按字面匹配
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+func (r *BaseRepository[T, PT]) Count(ctx context.Context, cond builder.Cond) (int64, result.AppError) {
+	count, err := r.query(ctx, cond).Count(new(T))
+	if err != nil {
+		return 0, dbError(err)
+	}
+
+	return count, nil
+}
+
+func (r *BaseRepository[T, PT]) Exists(ctx context.Context, cond builder.Cond) (bool, result.AppError) {
+	exists, err := r.query(ctx, cond).Exist(new(T))
+	if err != nil {
+		return false, dbError(err)
+	}
+
+	return exists, nil
+}
+
+// Insert 插入记录，成功后 entity 的 ID、CreatedTime、UpdatedTime 会被填充
+func (r *BaseRepository[T, PT]) Insert(ctx context.Context, entity *T) result.AppError {
+	if _, err := r.session(ctx).Insert(entity); err != nil {
+		return dbError(err)
+	}
+
+	return nil
+}
+
+// Update 只更新 cols 中的列，updated_time 会自动更新。必须显式指定列，避免零值字段被忽略或误更新。
+// 记录不存在或已删除时返回 CodeRecordNotFound。
+func (r *BaseRepository[T, PT]) Update(ctx context.Context, id uint64, entity *T, cols ...string) result.AppError {
+	if len(cols) == 0 {
+		return result.NewAppError(constant.CodeRuntimeError, "update 需要指定更新的列", true)
+	}
+
+	affected, err := r.query(ctx, builder.Eq{"id": id}).Cols(append(cols, "updated_time")...).Update(entity)
+	if err != nil {
+		return dbError(err)
+	}
+	if affected == 0 {
+		return result.NewAppError(constant.CodeRecordNotFound, "record not found")
+	}
+
+	return nil
+}
+
+// SoftDelete 把记录标记为已删除并记录删除时间，记录不存在或已删除时返回 CodeRecordNotFound
+func (r *BaseRepository[T, PT]) SoftDelete(ctx context.Context, id uint64) result.AppError {
+	entity := PT(new(T))
+	entity.Base().Deleted = true
+	entity.Base().DeletedTime = time.Now().UnixMilli()
+
+	return r.setDeleted(ctx, builder.Eq{"id": id, "deleted_time": 0}, entity)
+}
+
+// Restore 恢复已删除的记录，记录不存在或未删除时返回 CodeRecordNotFound。
+// 删除之后又创建了同名的记录时，恢复会违反唯一索引，返回 CodeDBError。
+func (r *BaseRepository[T, PT]) Restore(ctx context.Context, id uint64) result.AppError {
+	entity := PT(new(T))
+	return r.setDeleted(ctx, builder.And(builder.Eq{"id": id}, builder.Gt{"deleted_time": 0}), entity)
+}
+
+func (r *BaseRepository[T, PT]) setDeleted(ctx context.Context, cond builder.Cond, entity PT) result.AppError {
+	affected, err := r.session(ctx).
+		Where(cond).
+		Cols("deleted", "deleted_time", "updated_time").
+		Update(entity)
+	if err != nil {
+		return dbError(err)
+	}
+	if affected == 0 {
+		return result.NewAppError(constant.CodeRecordNotFound, "record not found")
+	}
+
+	return nil
+}
+
+// Purge 物理删除 before 之前软删除的记录，返回删除的行数。
+// 关联表中引用这些记录的数据需要调用方自行处理。
+func (r *BaseRepository[T, PT]) Purge(ctx context.Context, before time.Time) (int64, result.AppError) {
+	affected, err := r.session(ctx).
+		Where(builder.And(builder.Gt{"deleted_time": 0}, builder.Lt{"deleted_time": before.UnixMilli()})).
+		Delete(new(T))
+	if err != nil {
+		return 0, dbError(err)
+	}
+
+	return affected, nil
+}
+
+// dbError 数据库错误统一使用 CodeDBError，并记录调用栈；ctx 超时或取消导致的错误使用 CodeRequestTimeout
+func dbError(err error) result.AppError {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return result.NewAppErrorFromError(constant.CodeRequestTimeout, err)
+	}
+	return result.NewAppErrorFromError(constant.CodeDBError, err, true)
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"testing"
+
+	"my-web-template/internal/entity/request"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"bob", "bob"},
+		{"100%", "100!%"},
+		{"a_b", "a!_b"},
+		{"wow!", "wow!!"},
+		{"!%_", "!!!%!_"},
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.value); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPageColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    request.PageRequest
+		wantErr bool
+	}{
+		{"valid", request.PageRequest{
+			Sorts:   []request.SortField{{Column: "created_time", Desc: true}, {Column: "id"}},
+			Filters: []request.Filter{{Column: "state", Op: request.FilterEq, Values: []any{1}}},
+		}, false},
+		{"empty", request.PageRequest{}, false},
+		{"sort injection", request.PageRequest{Sorts: []request.SortField{{Column: "id; DROP TABLE app_user"}}}, true},
+		{"filter injection", request.PageRequest{Filters: []request.Filter{{Column: "1=1) OR (1", Op: request.FilterEq}}}, true},
+		{"quoted column", request.PageRequest{Sorts: []request.SortField{{Column: "`id`"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkPageColumns(&tt.page); (err != nil) != tt.wantErr {
+				t.Fatalf("checkPageColumns = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/model"
+	"my-web-template/internal/result"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+type RoleRepositoryInterface interface {
+	SaveRole(ctx context.Context, name, description string) (*model.AppRoleModel, result.AppError)
+	GetRoleByName(ctx context.Context, name string) (*model.AppRoleModel, result.AppError)
+	SavePermission(ctx context.Context, code, description string) (*model.AppPermissionModel, result.AppError)
+	GetPermissionByCode(ctx context.Context, code string) (*model.AppPermissionModel, result.AppError)
+	GrantPermission(ctx context.Context, roleId, permissionId uint64) result.AppError
+	RevokePermission(ctx context.Context, roleId, permissionId uint64) result.AppError
+	AssignRole(ctx context.Context, userId, roleId uint64) result.AppError
+	UnassignRole(ctx context.Context, userId, roleId uint64) result.AppError
+	GetPermissionCodesByUserId(ctx context.Context, userId uint64) ([]string, result.AppError)
+	GetUserIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, result.AppError)
+	PurgeDeletedRoles(ctx context.Context, before time.Time) (int64, result.AppError)
+	PurgeDeletedPermissions(ctx context.Context, before time.Time) (int64, result.AppError)
+	PurgeOrphanLinks(ctx context.Context) (int64, result.AppError)
+}
+
+type RoleRepository struct {
+	db              *xorm.Engine
+	roles           *BaseRepository[model.AppRoleModel, *model.AppRoleModel]
+	permissions     *BaseRepository[model.AppPermissionModel, *model.AppPermissionModel]
+	rolePermissions *BaseRepository[model.AppRolePermissionModel, *model.AppRolePermissionModel]
+	userRoles       *BaseRepository[model.AppUserRoleModel, *model.AppUserRoleModel]
+	logger          *zap.SugaredLogger
+}
+
+func NewRoleRepository(db *xorm.Engine, logger *zap.SugaredLogger) *RoleRepository {
+	return &RoleRepository{
+		db:              db,
+		roles:           NewBaseRepository[model.AppRoleModel](db),
+		permissions:     NewBaseRepository[model.AppPermissionModel](db),
+		rolePermissions: NewBaseRepository[model.AppRolePermissionModel](db),
+		userRoles:       NewBaseRepository[model.AppUserRoleModel](db),
+		logger:          logger,
+	}
+}
+
+func (r *RoleRepository) SaveRole(ctx context.Context, name, description string) (*model.AppRoleModel, result.AppError) {
+	role := &model.AppRoleModel{
+		Name:        name,
+		Description: description,
+	}
+	if err := r.roles.Insert(ctx, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *RoleRepository) GetRoleByName(ctx context.Context, name string) (*model.AppRoleModel, result.AppError) {
+	return r.roles.GetBy(ctx, builder.Eq{"name": name})
+}
+
+func (r *RoleRepository) SavePermission(ctx context.Context, code, description string) (*model.AppPermissionModel, result.AppError) {
+	permission := &model.AppPermissionModel{
+		Code:        code,
+		Description: description,
+	}
+	if err := r.permissions.Insert(ctx, permission); err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+func (r *RoleRepository) GetPermissionByCode(ctx context.Context, code string) (*model.AppPermissionModel, result.AppError) {
+	return r.permissions.GetBy(ctx, builder.Eq{"code": code})
+}
+
+// GrantPermission 给角色授予权限，已经授予过时不做任何操作。
+// (role_id, permission_id) 上有唯一索引，并发授予同一个权限时只有一个插入成功，其余的视为已经授予。
+func (r *RoleRepository) GrantPermission(ctx context.Context, roleId, permissionId uint64) result.AppError {
+	cond := builder.Eq{"role_id": roleId, "permission_id": permissionId}
+	exists, err := r.rolePermissions.Exists(ctx, cond)
+	if err != nil || exists {
+		return err
+	}
+
+	if err := r.rolePermissions.Insert(ctx, &model.AppRolePermissionModel{RoleId: roleId, PermissionId: permissionId}); err != nil {
+		return ignoreIfExists(ctx, r.rolePermissions, cond, err)
+	}
+	return nil
+}
+
+func (r *RoleRepository) RevokePermission(ctx context.Context, roleId, permissionId uint64) result.AppError {
+	_, err := dbSession(r.db, ctx).Where("role_id = ? AND permission_id = ?", roleId, permissionId).Delete(&model.AppRolePermissionModel{})
+	if err != nil {
+		return dbError(err)
+	}
+
+	return nil
+}
+
+// AssignRole 给用户分配角色，已经分配过时不做任何操作。
+// (user_id, role_id) 上有唯一索引，并发分配同一个角色时只有一个插入成功，其余的视为已经分配。
+func (r *RoleRepository) AssignRole(ctx context.Context, userId, roleId uint64) result.AppError {
+	cond := builder.Eq{"user_id": userId, "role_id": roleId}
+	exists, err := r.userRoles.Exists(ctx, cond)
+	if err != nil || exists {
+		return err
+	}
+
+	if err := r.userRoles.Insert(ctx, &model.AppUserRoleModel{UserId: userId, RoleId: roleId}); err != nil {
+		return ignoreIfExists(ctx, r.userRoles, cond, err)
+	}
+	return nil
+}
+
+func (r *RoleRepository) UnassignRole(ctx context.Context, userId, roleId uint64) result.AppError {
+	_, err := dbSession(r.db, ctx).Where("user_id = ? AND role_id = ?", userId, roleId).Delete(&model.AppUserRoleModel{})
+	if err != nil {
+		return dbError(err)
+	}
+
+	return nil
+}
+
+// GetPermissionCodesByUserId 获取用户通过所有角色获得的权限，结果已去重；
+// 已删除的用户、角色、权限不计算在内，被禁用的用户没有任何权限
+func (r *RoleRepository) GetPermissionCodesByUserId(ctx context.Context, userId uint64) ([]string, result.AppError) {
+	codes := make([]string, 0)
+	err := dbSession(r.db, ctx).Table("app_permission").Alias("p").
+		Join("INNER", []string{"app_role_permission", "rp"}, "rp.permission_id = p.id").
+		Join("INNER", []string{"app_user_role", "ur"}, "ur.role_id = rp.role_id").
+		Join("INNER", []string{"app_role", "r"}, "r.id = ur.role_id").
+		Join("INNER", []string{"app_user", "u"}, "u.id = ur.user_id").
+		Where("ur.user_id = ? AND u.state = ? AND p.deleted_time = 0 AND r.deleted_time = 0 AND u.deleted_time = 0",
+			userId, constant.UserStatusActive).
+		Distinct("p.code").
+		Find(&codes)
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	return codes, nil
+}
+
+func (r *RoleRepository) GetUserIdsByRoleId(ctx context.Context, roleId uint64) ([]uint64, result.AppError) {
+	userIds := make([]uint64, 0)
+	err := dbSession(r.db, ctx).Table(&model.AppUserRoleModel{}).Where("role_id = ?", roleId).Cols("user_id").Find(&userIds)
+	if err != nil {
+		return nil, dbError(err)
+	}
+
+	return userIds, nil
+}
+
+// PurgeDeletedRoles 物理删除 before 之前软删除的角色，引用这些角色的关联关系由 PurgeOrphanLinks 清理
+func (r *RoleRepository) PurgeDeletedRoles(ctx context.Context, before time.Time) (int64, result.AppError) {
+	return r.roles.Purge(ctx, before)
+}
+
+// PurgeDeletedPermissions 物理删除 before 之前软删除的权限，引用这些权限的关联关系由 PurgeOrphanLinks 清理
+func (r *RoleRepository) PurgeDeletedPermissions(ctx context.Context, before time.Time) (int64, result.AppError) {
+	return r.permissions.Purge(ctx, before)
+}
+
+// PurgeOrphanLinks 删除引用了已经不存在的用户、角色或权限的关联关系，返回删除的行数
+func (r *RoleRepository) PurgeOrphanLinks(ctx context.Context) (int64, result.AppError) {
+	rolePermissions, err := dbSession(r.db, ctx).Where(builder.Or(
+		builder.NotIn("role_id", builder.Select("id").From("app_role")),
+		builder.NotIn("permission_id", builder.Select("id").From("app_permission")),
+	)).Delete(&model.AppRolePermissionModel{})
+	if err != nil {
+		return 0, dbError(err)
+	}
+
+	userRoles, err := dbSession(r.db, ctx).Where(builder.Or(
+		builder.NotIn("user_id", builder.Select("id").From("app_user")),
+		builder.NotIn("role_id", builder.Select("id").From("app_role")),
+	)).Delete(&model.AppUserRoleModel{})
+	if err != nil {
+		return 0, dbError(err)
+	}
+
+	return rolePermissions + userRoles, nil
+}
+
+// ignoreIfExists 插入关联关系失败后检查记录是否已经被其他请求插入，是则忽略插入错误。
+// PostgreSQL 事务中插入失败后整个事务都不可用，再次查询也会失败，此时返回原来的错误。
+func ignoreIfExists[T any, PT Entity[T]](ctx context.Context, repo *BaseRepository[T, PT], cond builder.Cond, insertErr result.AppError) result.AppError {
+	if exists, err := repo.Exists(ctx, cond); err == nil && exists {
+		return nil
+	}
+	return insertErr
+}
+
+var _ RoleRepositoryInterface = (*RoleRepository)(nil)
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/logging"
+	"my-web-template/internal/result"
+	"xorm.io/xorm"
+)
+
+// Propagation 已经在事务中时，新的 Transaction 调用如何处理
+type Propagation int
+
+const (
+	// PropagationRequired 默认，加入已有的事务；fn 返回错误时整个事务在最外层回滚
+	PropagationRequired Propagation = iota
+	// PropagationNested 在已有的事务中创建 savepoint，fn 返回错误时只回滚到 savepoint，外层事务可以继续
+	PropagationNested
+)
+
+// TxOption Transaction 的可选项
+type TxOption func(*txOptions)
+
+type txOptions struct {
+	propagation Propagation
+	maxRetries  int
+}
+
+// WithPropagation 设置已经在事务中时的处理方式
+func WithPropagation(propagation Propagation) TxOption {
+	return func(o *txOptions) {
+		o.propagation = propagation
+	}
+}
+
+// WithRetry 遇到死锁或序列化失败时重新执行整个事务，最多重试 n 次。
+// 只对最外层事务生效，fn 需要可以安全地重复执行。
+func WithRetry(n int) TxOption {
+	return func(o *txOptions) {
+		o.maxRetries = n
+	}
+}
+
+type TxManagerInterface interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) result.AppError, opts ...TxOption) result.AppError
+}
+
+// TxManager 在 service 层把多个 repository 调用放到同一个事务中：
+//
+//	err := txManager.Transaction(ctx, func(ctx context.Context) result.AppError {
+//		user, err := userRepository.SaveUser(ctx, ...)
+//		if err != nil {
+//			return err
+//		}
+//		return roleRepository.AssignRole(ctx, user.ID, roleId)
+//	})
+//
+// 事务保存在传给 fn 的 ctx 中，repository 通过 dbSession 自动使用它，所以 fn 内部必须使用这个 ctx。
+// fn 返回 AppError 或 panic 时回滚，否则提交。事务 session 不是并发安全的，fn 内部不要在多个 goroutine 中访问数据库。
+type TxManager struct {
+	db     *xorm.Engine
+	logger *zap.SugaredLogger
+}
+
+func NewTxManager(db *xorm.Engine, logger *zap.SugaredLogger) *TxManager {
+	return &TxManager{
+		db:     db,
+		logger: logger,
+	}
+}
+
+type txKey struct{}
+
+// txState ctx 中保存的当前事务
+type txState struct {
+	session *xorm.Session
+	// savepoints 已经创建的 savepoint 个数，用于生成名称
+	savepoints int
+	// rollbackOnly 加入事务的调用失败后，即使外层忽略了错误也不能提交
+	rollbackOnly bool
+}
+
+// dbSession 返回当前 ctx 应该使用的 session：在事务中时返回事务的 session，否则返回执行一次操作后自动关闭的 session
+func dbSession(db *xorm.Engine, ctx context.Context) *xorm.Session {
+	if tx, ok := ctx.Value(txKey{}).(*txState); ok {
+		return tx.session.Context(ctx)
+	}
+	return db.Context(ctx)
+}
+
+// InTransaction ctx 是否处于事务中
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// Transaction 在事务中执行 fn，已经在事务中时按 Propagation 加入事务或创建 savepoint
+func (m *TxManager) Transaction(ctx context.Context, fn func(ctx context.Context) result.AppError, opts ...TxOption) result.AppError {
+	options := &txOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if tx, ok := ctx.Value(txKey{}).(*txState); ok {
+		if options.propagation == PropagationNested {
+			return m.savepoint(ctx, tx, fn)
+		}
+		err := fn(ctx)
+		if err != nil {
+			tx.rollbackOnly = true
+		}
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.transaction(ctx, fn)
+		if err == nil || attempt >= options.maxRetries || !isRetryable(err) {
+			return err
+		}
+		delay := time.Duration(attempt+1) * 20 * time.Millisecond
+		logging.FromContext(ctx).Warnf("事务遇到死锁或序列化失败，%s 后第 %d 次重试: %v", delay, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return dbError(ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// transaction 开启最外层事务并执行 fn
+func (m *TxManager) transaction(ctx context.Context, fn func(ctx context.Context) result.AppError) result.AppError {
+	session := m.db.NewSession().Context(ctx)
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		return dbError(err)
+	}
+
+	tx := &txState{session: session}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if err := session.Rollback(); err != nil {
+			m.logger.Errorf("事务回滚失败: %v", err)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+	if tx.rollbackOnly {
+		return result.NewAppError(constant.CodeDBError, "transaction has been marked as rollback-only")
+	}
+	if err := session.Commit(); err != nil {
+		return dbError(err)
+	}
+	committed = true
+
+	return nil
+}
+
+// savepoint 在已有事务中创建 savepoint 执行 fn，失败或 panic 时回滚到 savepoint
+func (m *TxManager) savepoint(ctx context.Context, tx *txState, fn func(ctx context.Context) result.AppError) result.AppError {
+	tx.savepoints++
+	name := fmt.Sprintf("sp_%d", tx.savepoints)
+	if _, err := tx.session.Exec("SAVEPOINT " + name); err != nil {
+		return dbError(err)
+	}
+
+	released := false
+	defer func() {
+		if released {
+			return
+		}
+		if _, err := tx.session.Exec("ROLLBACK TO SAVEPOINT " + name); err != nil {
+			m.logger.Errorf("回滚到 savepoint %s 失败: %v", name, err)
+			tx.rollbackOnly = true
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	if _, err := tx.session.Exec("RELEASE SAVEPOINT " + name); err != nil {
+		return dbError(err)
+	}
+	released = true
+
+	return nil
+}
+
+// isRetryable 是否是重新执行事务可能成功的错误：MySQL 的死锁、锁等待超时，PostgreSQL 的序列化失败、死锁
+func isRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213 || mysqlErr.Number == 1205
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	return false
+}
+
+var _ TxManagerInterface = (*TxManager)(nil)
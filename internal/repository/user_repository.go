@@ -1,57 +1,143 @@
 package repository
 
 import (
+	"context"
+	"strings"
+	"time"
+
 	"go.uber.org/zap"
 	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
 	"my-web-template/internal/model"
 	"my-web-template/internal/result"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
 type UserRepositoryInterface interface {
-	SaveUser(username, email, password string) (*model.AppUserModel, result.AppError)
-	GetUserByUsername(username string) (*model.AppUserModel, result.AppError)
+	SaveUser(ctx context.Context, username, email, password string) (*model.AppUserModel, result.AppError)
+	GetUserByUsername(ctx context.Context, username string) (*model.AppUserModel, result.AppError)
+	FindUsersByAccount(ctx context.Context, account string) ([]*model.AppUserModel, result.AppError)
+	AccountExists(ctx context.Context, username, email string) (bool, result.AppError)
+	GetUserById(ctx context.Context, userId uint64) (*model.AppUserModel, result.AppError)
+	UpdatePassword(ctx context.Context, userId uint64, password string) result.AppError
+	UpdateState(ctx context.Context, userId uint64, state uint8) result.AppError
+	DeleteUser(ctx context.Context, userId uint64) result.AppError
+	GetDeletedUserByUsername(ctx context.Context, username string) (*model.AppUserModel, result.AppError)
+	RestoreUser(ctx context.Context, userId uint64) result.AppError
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, result.AppError)
+	FindUsers(ctx context.Context, page *request.PageRequest) ([]*model.AppUserModel, int64, result.AppError)
 }
 
 type UserRepository struct {
 	db     *xorm.Engine
+	users  *BaseRepository[model.AppUserModel, *model.AppUserModel]
 	logger *zap.SugaredLogger
 }
 
 func NewUserRepository(db *xorm.Engine, logger *zap.SugaredLogger) *UserRepository {
 	return &UserRepository{
 		db:     db,
+		users:  NewBaseRepository[model.AppUserModel](db),
 		logger: logger,
 	}
 }
 
-func (u *UserRepository) SaveUser(username, email, password string) (*model.AppUserModel, result.AppError) {
+// SaveUser 用户名和邮箱统一保存为小写，数据库中 LOWER(username)、LOWER(email) 上的唯一索引保证不会重复
+func (u *UserRepository) SaveUser(ctx context.Context, username, email, password string) (*model.AppUserModel, result.AppError) {
 	example := &model.AppUserModel{
-		Username: username,
-		Email:    email,
+		Username: strings.ToLower(username),
+		Email:    strings.ToLower(email),
 		Password: password,
 		State:    constant.UserStatusActive,
 	}
-	_, err := u.db.Insert(example)
-	if err != nil {
-		return nil, result.NewAppErrorFromError(constant.CodeDBError, err, true)
+	if err := u.users.Insert(ctx, example); err != nil {
+		return nil, err
 	}
 
 	return example, nil
 }
 
-func (u *UserRepository) GetUserByUsername(username string) (*model.AppUserModel, result.AppError) {
-	user := &model.AppUserModel{}
+// GetUserByUsername 不区分大小写
+func (u *UserRepository) GetUserByUsername(ctx context.Context, username string) (*model.AppUserModel, result.AppError) {
+	return u.users.GetBy(ctx, builder.Expr("LOWER(username) = ?", strings.ToLower(username)))
+}
+
+// FindUsersByAccount 通过用户名或邮箱查找用户，不区分大小写。
+// 旧数据中一个用户的用户名可能和另一个用户的邮箱相同，所以可能返回多个用户，由调用方决定如何处理。
+func (u *UserRepository) FindUsersByAccount(ctx context.Context, account string) ([]*model.AppUserModel, result.AppError) {
+	account = strings.ToLower(account)
+	return u.users.FindBy(ctx, builder.Expr("LOWER(username) = ? OR LOWER(email) = ?", account, account))
+}
+
+// AccountExists 判断 username 或 email 是否已经被未删除的用户用作用户名或邮箱，不区分大小写
+func (u *UserRepository) AccountExists(ctx context.Context, username, email string) (bool, result.AppError) {
+	accounts := []string{strings.ToLower(username), strings.ToLower(email)}
+	return u.users.Exists(ctx, builder.Or(
+		builder.In("LOWER(username)", accounts),
+		builder.In("LOWER(email)", accounts),
+	))
+}
+
+// FindUsers 分页查询未删除的用户
+func (u *UserRepository) FindUsers(ctx context.Context, page *request.PageRequest) ([]*model.AppUserModel, int64, result.AppError) {
+	return u.users.FindPage(ctx, nil, page)
+}
+
+func (u *UserRepository) GetUserById(ctx context.Context, userId uint64) (*model.AppUserModel, result.AppError) {
+	return u.users.GetByID(ctx, userId)
+}
+
+// UpdatePassword 只更新密码字段，password 需要是已经计算好的 hash
+func (u *UserRepository) UpdatePassword(ctx context.Context, userId uint64, password string) result.AppError {
+	return u.users.Update(ctx, userId, &model.AppUserModel{Password: password}, "password")
+}
+
+func (u *UserRepository) UpdateState(ctx context.Context, userId uint64, state uint8) result.AppError {
+	return u.users.Update(ctx, userId, &model.AppUserModel{State: state}, "state")
+}
+
+// DeleteUser 软删除用户，删除后同名的用户可以重新注册
+func (u *UserRepository) DeleteUser(ctx context.Context, userId uint64) result.AppError {
+	return u.users.SoftDelete(ctx, userId)
+}
+
+// GetDeletedUserByUsername 查找已删除的用户，用户名不区分大小写，同名的用户被删除过多次时返回最近删除的
+func (u *UserRepository) GetDeletedUserByUsername(ctx context.Context, username string) (*model.AppUserModel, result.AppError) {
+	users, err := u.users.OnlyTrashed().FindBy(ctx, builder.Expr("LOWER(username) = ?", strings.ToLower(username)))
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+
+	latest := users[0]
+	for _, user := range users[1:] {
+		if user.DeletedTime > latest.DeletedTime {
+			latest = user
+		}
+	}
+	return latest, nil
+}
+
+func (u *UserRepository) RestoreUser(ctx context.Context, userId uint64) result.AppError {
+	return u.users.Restore(ctx, userId)
+}
+
+// PurgeDeletedUsers 物理删除 before 之前软删除的用户，以及这些用户的角色关联
+func (u *UserRepository) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, result.AppError) {
+	users, err := u.users.OnlyTrashed().FindBy(ctx, builder.Lt{"deleted_time": before.UnixMilli()})
+	if err != nil || len(users) == 0 {
+		return 0, err
+	}
 
-	exists, err := u.db.Where("username = ? AND deleted = false", username).Get(user)
-	if err != nil {
-		return nil, result.NewAppErrorFromError(constant.CodeDBError, err, true)
+	userIds := make([]uint64, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
 	}
-	if !exists {
-		return nil, nil
+	if _, err := dbSession(u.db, ctx).In("user_id", userIds).Delete(&model.AppUserRoleModel{}); err != nil {
+		return 0, dbError(err)
 	}
 
-	return user, nil
+	return u.users.Purge(ctx, before)
 }
 
 // 确保接口正确实现，如果 UserRepository 没有实现 UserRepositoryInterface，那么这里会报错
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"my-web-template/internal/model"
+	"xorm.io/xorm"
+	"xorm.io/xorm/names"
+)
+
+// newTestUserRepository 使用内存中的 sqlite3 数据库，只有一个连接，保证所有查询使用同一个数据库。
+// mapper 和 bootstrap 中的设置保持一致
+func newTestUserRepository(t *testing.T) *UserRepository {
+	t.Helper()
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("xorm.NewEngine: %v", err)
+	}
+	engine.SetMaxOpenConns(1)
+	engine.SetMapper(names.GonicMapper{})
+	t.Cleanup(func() { _ = engine.Close() })
+	if err := engine.Sync(new(model.AppUserModel)); err != nil {
+		t.Fatalf("engine.Sync: %v", err)
+	}
+	return NewUserRepository(engine, zap.NewNop().Sugar())
+}
+
+func TestGetDeletedUserByUsername(t *testing.T) {
+	repo := newTestUserRepository(t)
+	ctx := context.Background()
+
+	// 旧版本保存的用户名可能包含大写字母；同名用户被删除过两次，最后一个没有删除
+	users := []*model.AppUserModel{
+		{BaseModel: model.BaseModel{Deleted: true, DeletedTime: 1000}, Username: "Alice", Email: "alice@example.com"},
+		{BaseModel: model.BaseModel{Deleted: true, DeletedTime: 2000}, Username: "alice", Email: "alice@example.com"},
+		{Username: "alice", Email: "alice@example.com"},
+	}
+	for _, user := range users {
+		if _, err := repo.db.Insert(user); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+
+	for _, username := range []string{"alice", "Alice", "ALICE"} {
+		user, err := repo.GetDeletedUserByUsername(ctx, username)
+		if err != nil {
+			t.Fatalf("GetDeletedUserByUsername(%q): %v", username, err)
+		}
+		if user == nil || user.ID != users[1].ID {
+			t.Errorf("GetDeletedUserByUsername(%q) = %+v, want the latest deleted user %d", username, user, users[1].ID)
+		}
+	}
+
+	user, err := repo.GetDeletedUserByUsername(ctx, "bob")
+	if err != nil || user != nil {
+		t.Errorf("GetDeletedUserByUsername(bob) = %+v, %v; want nil, nil", user, err)
+	}
+}
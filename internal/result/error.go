@@ -16,13 +16,17 @@ type appError struct {
 	Code       constant.ResultCode
 	Message    string
 	ErrorStack string
+	// Data 返回给前端的附加数据，例如参数校验失败的字段列表
+	Data any
+	// cause NewAppErrorFromError 传入的原始错误，可以通过 errors.Is/As 判断
+	cause error
 }
 
 func (e *appError) ToAppResult() *AppResult {
 	return &AppResult{
 		Code:       e.Code,
 		Message:    e.Message,
-		Data:       nil,
+		Data:       e.Data,
 		ErrorStack: e.ErrorStack,
 	}
 }
@@ -31,6 +35,10 @@ func (e *appError) Error() string {
 	return fmt.Sprintf("[APP_ERROR|%s|%d] Message=%s\nStack=%s", constant.GetResultCodeName(e.Code), e.Code, e.Message, e.ErrorStack)
 }
 
+func (e *appError) Unwrap() error {
+	return e.cause
+}
+
 func NewAppError(code constant.ResultCode, message string, withStack ...bool) AppError {
 	stackString := ""
 	if len(withStack) > 0 && withStack[0] {
@@ -45,6 +53,15 @@ func NewAppError(code constant.ResultCode, message string, withStack ...bool) Ap
 	}
 }
 
+// NewAppErrorWithData 创建带有附加数据的 AppError，data 会放到 AppResult.data 中
+func NewAppErrorWithData(code constant.ResultCode, message string, data any) AppError {
+	return &appError{
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}
+}
+
 func NewAppErrorFromError(code constant.ResultCode, err error, withStack ...bool) AppError {
 	stackString := ""
 	if len(withStack) > 0 && withStack[0] {
@@ -56,5 +73,6 @@ func NewAppErrorFromError(code constant.ResultCode, err error, withStack ...bool
 		Code:       code,
 		Message:    err.Error(),
 		ErrorStack: stackString,
+		cause:      err,
 	}
 }
@@ -11,6 +11,7 @@ type AppResult struct {
 	Message    string              `json:"message"`
 	Data       any                 `json:"data"`
 	ErrorStack string              `json:"error_stack,omitempty"`
+	RequestId  string              `json:"request_id,omitempty"`
 }
 
 func NewAppResult(code constant.ResultCode, message string, data any, withStack ...bool) *AppResult {
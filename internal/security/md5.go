@@ -0,0 +1,37 @@
+package security
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+const LegacyMD5ID = "md5"
+
+// LegacyMD5Verifier 兼容早期版本使用 hex(md5(password)) 存储的密码，只校验、不再生成。
+// 校验成功后 PasswordManager 会要求重新计算 hash，旧数据会在用户下次登录时被迁移。
+type LegacyMD5Verifier struct{}
+
+func NewLegacyMD5Verifier() *LegacyMD5Verifier {
+	return &LegacyMD5Verifier{}
+}
+
+func (v *LegacyMD5Verifier) ID() string {
+	return LegacyMD5ID
+}
+
+func (v *LegacyMD5Verifier) CanVerify(encoded string) bool {
+	if len(encoded) != hex.EncodedLen(md5.Size) {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
+
+func (v *LegacyMD5Verifier) Verify(password, encoded string) (bool, error) {
+	sum := md5.Sum([]byte(password))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(encoded)) == 1, nil
+}
+
+var _ PasswordVerifier = (*LegacyMD5Verifier)(nil)
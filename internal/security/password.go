@@ -0,0 +1,113 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"my-web-template/internal/config"
+)
+
+var (
+	// ErrUnknownHashFormat 无法识别密码 hash 的格式，通常是数据被篡改或使用了未注册的算法
+	ErrUnknownHashFormat = errors.New("unknown password hash format")
+	// ErrMalformedHash hash 格式可以识别，但内容不完整或参数不合法
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// PasswordVerifier 校验密码是否与已存储的 hash 匹配。
+// 只用于校验的历史算法（如 MD5）只需要实现这个接口。
+type PasswordVerifier interface {
+	// ID 算法标识，例如 argon2id、bcrypt
+	ID() string
+	// CanVerify 判断 encoded 是否是当前算法生成的 hash
+	CanVerify(encoded string) bool
+	// Verify 校验密码，不匹配时返回 false, nil
+	Verify(password, encoded string) (bool, error)
+}
+
+// PasswordHasher 可以生成新 hash 的算法
+type PasswordHasher interface {
+	PasswordVerifier
+	// Hash 生成自描述的 hash 字符串，算法和参数都编码在结果中
+	Hash(password string) (string, error)
+	// NeedsRehash 判断 encoded 使用的参数是否和当前配置不一致
+	NeedsRehash(encoded string) bool
+}
+
+// PasswordManager 使用当前配置的算法生成 hash，并能校验所有已注册算法生成的 hash。
+// 校验成功时如果发现 hash 使用的算法或参数已经过时，会提示调用方重新计算 hash。
+type PasswordManager struct {
+	current   PasswordHasher
+	verifiers []PasswordVerifier
+	// dummyHash 账号不存在时用于校验的 hash，创建时计算好，避免第一次使用时多一次计算
+	dummyHash string
+}
+
+// NewPasswordManager current 用于生成新的 hash，legacy 是只用于校验的历史算法
+func NewPasswordManager(current PasswordHasher, legacy ...PasswordVerifier) *PasswordManager {
+	verifiers := make([]PasswordVerifier, 0, len(legacy)+1)
+	verifiers = append(verifiers, current)
+	verifiers = append(verifiers, legacy...)
+	dummyHash, _ := current.Hash("dummy-password")
+	return &PasswordManager{
+		current:   current,
+		verifiers: verifiers,
+		dummyHash: dummyHash,
+	}
+}
+
+// NewPasswordManagerFromConfig 根据配置创建 PasswordManager。
+// 所有支持的算法都会注册为校验器，所以切换算法后旧的 hash 依然可以登录，并在登录时升级。
+func NewPasswordManagerFromConfig(appConfig *config.AppConfig) (*PasswordManager, error) {
+	cfg := appConfig.Password
+	argon2Hasher := NewArgon2idHasher(Argon2idParams{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+	})
+	bcryptHasher := NewBcryptHasher(cfg.BcryptCost)
+	md5Verifier := NewLegacyMD5Verifier()
+
+	switch strings.ToLower(strings.TrimSpace(cfg.Algorithm)) {
+	case "", Argon2idID:
+		return NewPasswordManager(argon2Hasher, bcryptHasher, md5Verifier), nil
+	case BcryptID:
+		return NewPasswordManager(bcryptHasher, argon2Hasher, md5Verifier), nil
+	default:
+		return nil, fmt.Errorf("不支持的密码 hash 算法: %s", cfg.Algorithm)
+	}
+}
+
+// Hash 使用当前算法计算密码 hash
+func (m *PasswordManager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+// Verify 校验密码。matched 表示密码是否正确；
+// needsRehash 仅在 matched 为 true 时有意义，表示应当用 Hash 重新计算并保存新的 hash。
+func (m *PasswordManager) Verify(password, encoded string) (matched bool, needsRehash bool, err error) {
+	for _, verifier := range m.verifiers {
+		if !verifier.CanVerify(encoded) {
+			continue
+		}
+		matched, err = verifier.Verify(password, encoded)
+		if err != nil || !matched {
+			return false, false, err
+		}
+		if verifier.ID() != m.current.ID() {
+			return true, true, nil
+		}
+		return true, m.current.NeedsRehash(encoded), nil
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
+
+// VerifyDummy 账号不存在时调用，用当前算法执行一次和正常登录耗时相同的校验，
+// 避免通过响应时间判断账号是否存在。结果总是不匹配。
+func (m *PasswordManager) VerifyDummy(password string) {
+	if m.dummyHash != "" {
+		_, _ = m.current.Verify(password, m.dummyHash)
+	}
+}
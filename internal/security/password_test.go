@@ -0,0 +1,190 @@
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 测试使用较小的参数，避免 argon2id 默认的 64 MiB 内存拖慢测试
+var testArgon2Params = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+	encoded, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if !hasher.CanVerify(encoded) {
+		t.Fatalf("CanVerify(%q) = false", encoded)
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"correct horse", true},
+		{"correct horse ", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		matched, err := hasher.Verify(tt.password, encoded)
+		if err != nil || matched != tt.want {
+			t.Errorf("Verify(%q) = %v, %v; want %v, nil", tt.password, matched, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	const salt, key = "c2FsdHNhbHRzYWx0c2FsdA", "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	tests := []struct {
+		name    string
+		encoded string
+		want    Argon2idParams
+		wantErr bool
+	}{
+		{"valid", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$" + key, Argon2idParams{65536, 3, 2}, false},
+		{"wrong algorithm", "$argon2i$v=19$m=65536,t=3,p=2$" + salt + "$" + key, Argon2idParams{}, true},
+		{"wrong version", "$argon2id$v=16$m=65536,t=3,p=2$" + salt + "$" + key, Argon2idParams{}, true},
+		{"missing part", "$argon2id$v=19$m=65536,t=3,p=2$" + salt, Argon2idParams{}, true},
+		{"zero memory", "$argon2id$v=19$m=0,t=3,p=2$" + salt + "$" + key, Argon2idParams{}, true},
+		{"bad params", "$argon2id$v=19$t=3,m=65536,p=2$" + salt + "$" + key, Argon2idParams{}, true},
+		{"bad salt", "$argon2id$v=19$m=65536,t=3,p=2$!!!$" + key, Argon2idParams{}, true},
+		{"empty key", "$argon2id$v=19$m=65536,t=3,p=2$" + salt + "$", Argon2idParams{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, _, _, err := decodeArgon2id(tt.encoded)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformedHash) {
+					t.Fatalf("err = %v, want ErrMalformedHash", err)
+				}
+				return
+			}
+			if err != nil || params != tt.want {
+				t.Fatalf("params = %+v, err = %v; want %+v", params, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	encoded, err := hasher.Hash("secret")
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+		want     bool
+		wantErr  error
+	}{
+		{"match", "secret", encoded, true, nil},
+		{"mismatch", "Secret", encoded, false, nil},
+		{"malformed", "secret", "$2a$04$short", false, ErrMalformedHash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := hasher.Verify(tt.password, tt.encoded)
+			if matched != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify = %v, %v; want %v, %v", matched, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+
+	if NewBcryptHasher(0).cost != bcrypt.DefaultCost {
+		t.Errorf("cost 0 should fall back to bcrypt.DefaultCost")
+	}
+}
+
+func TestLegacyMD5(t *testing.T) {
+	verifier := NewLegacyMD5Verifier()
+	// md5("password")
+	const encoded = "5f4dcc3b5aa765d61d8327deb882cf99"
+
+	tests := []struct {
+		name      string
+		password  string
+		encoded   string
+		canVerify bool
+		want      bool
+	}{
+		{"match", "password", encoded, true, true},
+		{"mismatch", "Password", encoded, true, false},
+		{"not hex", "password", "zf4dcc3b5aa765d61d8327deb882cf99", false, false},
+		{"wrong length", "password", encoded[:30], false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifier.CanVerify(tt.encoded); got != tt.canVerify {
+				t.Fatalf("CanVerify = %v, want %v", got, tt.canVerify)
+			}
+			if !tt.canVerify {
+				return
+			}
+			if matched, err := verifier.Verify(tt.password, tt.encoded); matched != tt.want || err != nil {
+				t.Fatalf("Verify = %v, %v; want %v, nil", matched, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPasswordManagerNeedsRehash(t *testing.T) {
+	argon2Hasher := NewArgon2idHasher(testArgon2Params)
+	weakerArgon2 := NewArgon2idHasher(Argon2idParams{Memory: 512, Iterations: 1, Parallelism: 1})
+	bcryptHasher := NewBcryptHasher(bcrypt.MinCost)
+	cheaperBcrypt := NewBcryptHasher(bcrypt.MinCost + 1)
+
+	hash := func(h PasswordHasher) string {
+		encoded, err := h.Hash("secret")
+		if err != nil {
+			t.Fatalf("Hash: %v", err)
+		}
+		return encoded
+	}
+
+	argon2Manager := NewPasswordManager(argon2Hasher, bcryptHasher, NewLegacyMD5Verifier())
+	bcryptManager := NewPasswordManager(bcryptHasher, argon2Hasher, NewLegacyMD5Verifier())
+
+	tests := []struct {
+		name            string
+		manager         *PasswordManager
+		password        string
+		encoded         string
+		wantMatched     bool
+		wantNeedsRehash bool
+		wantErr         error
+	}{
+		{"current argon2id", argon2Manager, "secret", hash(argon2Hasher), true, false, nil},
+		{"argon2id with old params", argon2Manager, "secret", hash(weakerArgon2), true, true, nil},
+		{"bcrypt while argon2id is current", argon2Manager, "secret", hash(bcryptHasher), true, true, nil},
+		{"legacy md5", argon2Manager, "secret", "5ebe2294ecd0e0f08eab7690d2a6ee69", true, true, nil},
+		{"current bcrypt", bcryptManager, "secret", hash(bcryptHasher), true, false, nil},
+		{"bcrypt with other cost", bcryptManager, "secret", hash(cheaperBcrypt), true, true, nil},
+		{"argon2id while bcrypt is current", bcryptManager, "secret", hash(argon2Hasher), true, true, nil},
+		{"wrong password never needs rehash", argon2Manager, "wrong", hash(bcryptHasher), false, false, nil},
+		{"unknown format", argon2Manager, "secret", "plain-text", false, false, ErrUnknownHashFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, needsRehash, err := tt.manager.Verify(tt.password, tt.encoded)
+			if matched != tt.wantMatched || needsRehash != tt.wantNeedsRehash || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify = %v, %v, %v; want %v, %v, %v",
+					matched, needsRehash, err, tt.wantMatched, tt.wantNeedsRehash, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyDummyUsesCurrentHasher(t *testing.T) {
+	manager := NewPasswordManager(NewArgon2idHasher(testArgon2Params))
+	if !manager.current.CanVerify(manager.dummyHash) {
+		t.Fatalf("dummy hash %q is not produced by the current hasher", manager.dummyHash)
+	}
+	// 不管输入什么都不能 panic，结果被丢弃
+	manager.VerifyDummy("anything")
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/vo"
+	"my-web-template/internal/model"
+	"my-web-template/internal/repository"
+	"my-web-template/internal/result"
+)
+
+type RoleServiceInterface interface {
+	CreateRole(ctx context.Context, name, description string) (*vo.RoleVO, result.AppError)
+	CreatePermission(ctx context.Context, code, description string) result.AppError
+	GrantPermission(ctx context.Context, roleName, permissionCode string) result.AppError
+	RevokePermission(ctx context.Context, roleName, permissionCode string) result.AppError
+	AssignRole(ctx context.Context, userId uint64, roleName string) result.AppError
+	UnassignRole(ctx context.Context, userId uint64, roleName string) result.AppError
+	GetUserPermissions(ctx context.Context, userId uint64) ([]string, result.AppError)
+	GetPermissionVersion(ctx context.Context, userId uint64) (string, result.AppError)
+	InvalidateUserPermissions(ctx context.Context, userId uint64) result.AppError
+	PurgeDeletedRoles(ctx context.Context, retention time.Duration) (int64, int64, result.AppError)
+}
+
+// RoleService 管理角色、权限以及用户和角色的关系。
+// 每个用户有一个权限版本号，保存在 session 使用的 storage 中，多个实例之间共享；
+// 用户的角色或角色的权限发生变化时更新版本号，session 中缓存的权限会因此失效。
+type RoleService struct {
+	roleRepository *repository.RoleRepository
+	txManager      *repository.TxManager
+	storage        fiber.Storage
+	logger         *zap.SugaredLogger
+}
+
+func NewRoleService(roleRepository *repository.RoleRepository, txManager *repository.TxManager, storage fiber.Storage, logger *zap.SugaredLogger) *RoleService {
+	return &RoleService{
+		roleRepository: roleRepository,
+		txManager:      txManager,
+		storage:        storage,
+		logger:         logger,
+	}
+}
+
+func (r *RoleService) CreateRole(ctx context.Context, name, description string) (*vo.RoleVO, result.AppError) {
+	role, err := r.roleRepository.SaveRole(ctx, name, description)
+	if err != nil {
+		return nil, err
+	}
+
+	return role.ToVO(), nil
+}
+
+func (r *RoleService) CreatePermission(ctx context.Context, code, description string) result.AppError {
+	_, err := r.roleRepository.SavePermission(ctx, code, description)
+	return err
+}
+
+func (r *RoleService) GrantPermission(ctx context.Context, roleName, permissionCode string) result.AppError {
+	role, permission, err := r.getRoleAndPermission(ctx, roleName, permissionCode)
+	if err != nil {
+		return err
+	}
+	if err := r.roleRepository.GrantPermission(ctx, role.ID, permission.ID); err != nil {
+		return err
+	}
+
+	return r.bumpRoleUsersVersion(ctx, role.ID)
+}
+
+func (r *RoleService) RevokePermission(ctx context.Context, roleName, permissionCode string) result.AppError {
+	role, permission, err := r.getRoleAndPermission(ctx, roleName, permissionCode)
+	if err != nil {
+		return err
+	}
+	if err := r.roleRepository.RevokePermission(ctx, role.ID, permission.ID); err != nil {
+		return err
+	}
+
+	return r.bumpRoleUsersVersion(ctx, role.ID)
+}
+
+func (r *RoleService) AssignRole(ctx context.Context, userId uint64, roleName string) result.AppError {
+	role, err := r.getRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := r.roleRepository.AssignRole(ctx, userId, role.ID); err != nil {
+		return err
+	}
+
+	return r.bumpPermissionVersion(ctx, userId)
+}
+
+// PurgeDeletedRoles 物理删除软删除时间超过 retention 的角色和权限，返回删除的角色数和权限数。
+// 同时清理引用了不存在的用户、角色或权限的关联关系；软删除的角色和权限已经不再生效，不需要让权限缓存失效。
+func (r *RoleService) PurgeDeletedRoles(ctx context.Context, retention time.Duration) (int64, int64, result.AppError) {
+	before := time.Now().Add(-retention)
+	var roles, permissions, links int64
+	err := r.txManager.Transaction(ctx, func(ctx context.Context) result.AppError {
+		var err result.AppError
+		if roles, err = r.roleRepository.PurgeDeletedRoles(ctx, before); err != nil {
+			return err
+		}
+		if permissions, err = r.roleRepository.PurgeDeletedPermissions(ctx, before); err != nil {
+			return err
+		}
+		links, err = r.roleRepository.PurgeOrphanLinks(ctx)
+		return err
+	}, repository.WithRetry(3))
+	if err != nil {
+		return 0, 0, err
+	}
+	if links > 0 {
+		r.logger.Infof("删除了 %d 条失效的角色、权限关联关系", links)
+	}
+
+	return roles, permissions, nil
+}
+
+func (r *RoleService) UnassignRole(ctx context.Context, userId uint64, roleName string) result.AppError {
+	role, err := r.getRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if err := r.roleRepository.UnassignRole(ctx, userId, role.ID); err != nil {
+		return err
+	}
+
+	return r.bumpPermissionVersion(ctx, userId)
+}
+
+func (r *RoleService) GetUserPermissions(ctx context.Context, userId uint64) ([]string, result.AppError) {
+	return r.roleRepository.GetPermissionCodesByUserId(ctx, userId)
+}
+
+// GetPermissionVersion 获取用户当前的权限版本号，从未变更过时返回空字符串
+func (r *RoleService) GetPermissionVersion(ctx context.Context, userId uint64) (string, result.AppError) {
+	value, err := r.storage.Get(permissionVersionKey(userId))
+	if err != nil {
+		return "", result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+
+	return string(value), nil
+}
+
+// InvalidateUserPermissions 让用户 session 中缓存的权限失效，例如用户被删除或恢复之后
+func (r *RoleService) InvalidateUserPermissions(ctx context.Context, userId uint64) result.AppError {
+	return r.bumpPermissionVersion(ctx, userId)
+}
+
+func (r *RoleService) getRole(ctx context.Context, roleName string) (*model.AppRoleModel, result.AppError) {
+	role, err := r.roleRepository.GetRoleByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, result.NewAppError(constant.CodeRecordNotFound, fmt.Sprintf("role %s not found", roleName))
+	}
+
+	return role, nil
+}
+
+func (r *RoleService) getRoleAndPermission(ctx context.Context, roleName, permissionCode string) (*model.AppRoleModel, *model.AppPermissionModel, result.AppError) {
+	role, err := r.getRole(ctx, roleName)
+	if err != nil {
+		return nil, nil, err
+	}
+	permission, err := r.roleRepository.GetPermissionByCode(ctx, permissionCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if permission == nil {
+		return nil, nil, result.NewAppError(constant.CodeRecordNotFound, fmt.Sprintf("permission %s not found", permissionCode))
+	}
+
+	return role, permission, nil
+}
+
+// bumpRoleUsersVersion 角色的权限变化后，拥有该角色的所有用户都需要重新加载权限
+func (r *RoleService) bumpRoleUsersVersion(ctx context.Context, roleId uint64) result.AppError {
+	userIds, err := r.roleRepository.GetUserIdsByRoleId(ctx, roleId)
+	if err != nil {
+		return err
+	}
+	for _, userId := range userIds {
+		if err := r.bumpPermissionVersion(ctx, userId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *RoleService) bumpPermissionVersion(ctx context.Context, userId uint64) result.AppError {
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := r.storage.Set(permissionVersionKey(userId), []byte(version), 0); err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+
+	return nil
+}
+
+func permissionVersionKey(userId uint64) string {
+	return fmt.Sprintf("rbac:permission_version:%d", userId)
+}
+
+var _ RoleServiceInterface = (*RoleService)(nil)
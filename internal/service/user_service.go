@@ -1,50 +1,315 @@
 package service
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
 	"my-web-template/internal/entity/vo"
+	"my-web-template/internal/logging"
+	"my-web-template/internal/model"
 	"my-web-template/internal/repository"
 	"my-web-template/internal/result"
+	"my-web-template/internal/security"
 )
 
 type UserServiceInterface interface {
-	SaveUser(username, email, password string) (*vo.UserVO, result.AppError)
-	GetUserByUsername(username string) (*vo.UserVO, result.AppError)
+	SaveUser(ctx context.Context, username, email, password string, roleNames ...string) (*vo.UserVO, result.AppError)
+	Register(ctx context.Context, username, email, password string) (*vo.UserVO, result.AppError)
+	GetUserByUsername(ctx context.Context, username string) (*vo.UserVO, result.AppError)
+	GetUserById(ctx context.Context, userId uint64) (*vo.UserVO, result.AppError)
+	GetUserByAccount(ctx context.Context, account string) (*vo.UserVO, result.AppError)
+	Authenticate(ctx context.Context, account, password string) (*vo.UserVO, result.AppError)
+	ChangePassword(ctx context.Context, account, password string) result.AppError
+	SetUserState(ctx context.Context, account string, state uint8) (*vo.UserVO, result.AppError)
+	DeleteUser(ctx context.Context, account string) (*vo.UserVO, result.AppError)
+	RestoreUser(ctx context.Context, username string) (*vo.UserVO, result.AppError)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, result.AppError)
+	ListUsers(ctx context.Context, page *request.PageRequest) (*vo.PageVO[*vo.UserVO], result.AppError)
 }
 
+// UserService defaultRoles 是通过 Register 注册的用户自动分配的角色
 type UserService struct {
-	userRepository *repository.UserRepository
-	logger         *zap.SugaredLogger
+	userRepository  *repository.UserRepository
+	roleRepository  *repository.RoleRepository
+	txManager       *repository.TxManager
+	passwordManager *security.PasswordManager
+	defaultRoles    []string
+	logger          *zap.SugaredLogger
 }
 
-func NewUserService(userRepository *repository.UserRepository, logger *zap.SugaredLogger) *UserService {
+func NewUserService(userRepository *repository.UserRepository, roleRepository *repository.RoleRepository, txManager *repository.TxManager,
+	passwordManager *security.PasswordManager, defaultRoles []string, logger *zap.SugaredLogger) *UserService {
 	return &UserService{
-		userRepository: userRepository,
-		logger:         logger,
+		userRepository:  userRepository,
+		roleRepository:  roleRepository,
+		txManager:       txManager,
+		passwordManager: passwordManager,
+		defaultRoles:    defaultRoles,
+		logger:          logger,
 	}
 }
 
-func (u *UserService) SaveUser(username, email, password string) (*vo.UserVO, result.AppError) {
-	sum := md5.Sum([]byte(password))
-	newPassword := hex.EncodeToString(sum[:])
-	user, err := u.userRepository.SaveUser(username, email, newPassword)
+// SaveUser 创建用户并分配角色，在同一个事务中执行，任意角色不存在时用户也不会被创建。
+// 用户名不能包含 @，用户名和邮箱不区分大小写，都不能和已有用户的用户名或邮箱相同，保证登录时账号只对应一个用户。
+func (u *UserService) SaveUser(ctx context.Context, username, email, password string, roleNames ...string) (*vo.UserVO, result.AppError) {
+	if strings.Contains(username, "@") {
+		return nil, result.NewAppError(constant.CodeParamError, "username must not contain @")
+	}
+
+	hashed, hashErr := u.passwordManager.Hash(password)
+	if hashErr != nil {
+		return nil, result.NewAppErrorFromError(constant.CodeRuntimeError, hashErr, true)
+	}
+
+	var user *model.AppUserModel
+	err := u.txManager.Transaction(ctx, func(ctx context.Context) result.AppError {
+		exists, err := u.userRepository.AccountExists(ctx, username, email)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return result.NewAppError(constant.CodeParamError, "username or email is already in use")
+		}
+		if user, err = u.userRepository.SaveUser(ctx, username, email, hashed); err != nil {
+			return err
+		}
+		for _, roleName := range roleNames {
+			role, err := u.roleRepository.GetRoleByName(ctx, roleName)
+			if err != nil {
+				return err
+			}
+			if role == nil {
+				return result.NewAppError(constant.CodeRecordNotFound, fmt.Sprintf("role %s not found", roleName))
+			}
+			if err := u.roleRepository.AssignRole(ctx, user.ID, role.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToVO(), nil
+}
+
+// Register 用户自行注册，分配配置的默认角色
+func (u *UserService) Register(ctx context.Context, username, email, password string) (*vo.UserVO, result.AppError) {
+	return u.SaveUser(ctx, username, email, password, u.defaultRoles...)
+}
+
+// GetUserByAccount 通过用户名或邮箱获取用户信息，不检查用户状态，用于管理操作
+func (u *UserService) GetUserByAccount(ctx context.Context, account string) (*vo.UserVO, result.AppError) {
+	user, err := u.getUserByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return user.ToVO(), nil
+}
+
+// ChangePassword 使用当前算法重新设置用户密码
+func (u *UserService) ChangePassword(ctx context.Context, account, password string) result.AppError {
+	user, err := u.getUserByAccount(ctx, account)
+	if err != nil {
+		return err
+	}
+
+	hashed, hashErr := u.passwordManager.Hash(password)
+	if hashErr != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, hashErr, true)
+	}
+
+	return u.userRepository.UpdatePassword(ctx, user.ID, hashed)
+}
+
+// SetUserState 修改用户状态，例如 constant.UserStatusDisabled。
+// 被禁用的用户不再拥有任何权限，调用方需要让用户 session 中缓存的权限失效。
+func (u *UserService) SetUserState(ctx context.Context, account string, state uint8) (*vo.UserVO, result.AppError) {
+	user, err := u.getUserByAccount(ctx, account)
 	if err != nil {
 		return nil, err
 	}
+	if err := u.userRepository.UpdateState(ctx, user.ID, state); err != nil {
+		return nil, err
+	}
 
+	user.State = state
 	return user.ToVO(), nil
 }
 
-func (u *UserService) GetUserByUsername(username string) (*vo.UserVO, result.AppError) {
-	user, err := u.userRepository.GetUserByUsername(username)
+// DeleteUser 软删除用户，删除后用户无法登录，同名的用户可以重新注册
+func (u *UserService) DeleteUser(ctx context.Context, account string) (*vo.UserVO, result.AppError) {
+	user, err := u.getUserByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.userRepository.DeleteUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Infof("用户 %d(%s) 已删除", user.ID, user.Username)
+	return user.ToVO(), nil
+}
+
+// RestoreUser 恢复最近删除的同名用户；删除后又注册了同名用户时无法恢复
+func (u *UserService) RestoreUser(ctx context.Context, username string) (*vo.UserVO, result.AppError) {
+	user, err := u.userRepository.GetDeletedUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, result.NewAppError(constant.CodeRecordNotFound, "deleted user not found")
+	}
+
+	exists, err := u.userRepository.AccountExists(ctx, user.Username, user.Email)
 	if err != nil {
 		return nil, err
 	}
+	if exists {
+		return nil, result.NewAppError(constant.CodeParamError, fmt.Sprintf("username or email of %s is already in use", username))
+	}
 
+	if err := u.userRepository.RestoreUser(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	logging.FromContext(ctx).Infof("用户 %d(%s) 已恢复", user.ID, user.Username)
 	return user.ToVO(), nil
 }
 
+// PurgeDeletedUsers 物理删除软删除时间超过 retention 的用户
+func (u *UserService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, result.AppError) {
+	var count int64
+	err := u.txManager.Transaction(ctx, func(ctx context.Context) result.AppError {
+		var err result.AppError
+		count, err = u.userRepository.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+		return err
+	}, repository.WithRetry(3))
+
+	return count, err
+}
+
+// getUserByAccount 账号对应多个用户时返回 CodeParamError，需要改用不会冲突的用户名或邮箱
+func (u *UserService) getUserByAccount(ctx context.Context, account string) (*model.AppUserModel, result.AppError) {
+	users, err := u.userRepository.FindUsersByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, result.NewAppError(constant.CodeRecordNotFound, "user not found")
+	}
+	if len(users) > 1 {
+		return nil, result.NewAppError(constant.CodeParamError, fmt.Sprintf("account %s matches multiple users", account))
+	}
+
+	return users[0], nil
+}
+
+// Authenticate 校验账号和密码，account 可以是用户名或邮箱，成功时返回用户信息。
+// 被禁用的用户无法登录；如果存储的 hash 使用了旧的算法或参数，会在校验成功后重新计算并保存。
+// 账号同时匹配多个用户时拒绝登录，避免用一个用户的密码登录到另一个用户。
+func (u *UserService) Authenticate(ctx context.Context, account, password string) (*vo.UserVO, result.AppError) {
+	users, err := u.userRepository.FindUsersByAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) != 1 {
+		if len(users) > 1 {
+			logging.FromContext(ctx).Warnf("账号 %s 匹配到 %d 个用户，拒绝登录", account, len(users))
+		}
+		u.passwordManager.VerifyDummy(password)
+		return nil, result.NewAppError(constant.CodeAuthFailed, "invalid username or password")
+	}
+	user := users[0]
+
+	matched, needsRehash, verifyErr := u.passwordManager.Verify(password, user.Password)
+	if trimmed := strings.TrimSpace(password); verifyErr == nil && !matched && trimmed != password {
+		// 早期版本计算 hash 之前会去掉密码前后的空白，这些 hash 需要用去掉空白的密码校验。
+		// 重新计算 hash 时也使用去掉空白的密码，保证带空白和不带空白的输入依然都能登录
+		if matched, needsRehash, verifyErr = u.passwordManager.Verify(trimmed, user.Password); matched {
+			password = trimmed
+		}
+	}
+	if verifyErr != nil {
+		logging.FromContext(ctx).Warnf("校验用户 %d 的密码失败: %v", user.ID, verifyErr)
+		return nil, result.NewAppError(constant.CodeAuthFailed, "invalid username or password")
+	}
+	if !matched {
+		return nil, result.NewAppError(constant.CodeAuthFailed, "invalid username or password")
+	}
+	if user.State == constant.UserStatusDisabled {
+		return nil, result.NewAppError(constant.CodeUserDisabled, "user is disabled")
+	}
+
+	if needsRehash {
+		u.rehashPassword(ctx, user.ID, password)
+	}
+
+	return user.ToVO(), nil
+}
+
+// rehashPassword 用当前算法重新计算密码 hash，失败时只记录日志，不影响本次登录
+func (u *UserService) rehashPassword(ctx context.Context, userId uint64, password string) {
+	hashed, err := u.passwordManager.Hash(password)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("重新计算用户 %d 的密码 hash 失败: %v", userId, err)
+		return
+	}
+	if err := u.userRepository.UpdatePassword(ctx, userId, hashed); err != nil {
+		logging.FromContext(ctx).Warnf("保存用户 %d 的新密码 hash 失败: %v", userId, err)
+		return
+	}
+	logging.FromContext(ctx).Infof("用户 %d 的密码 hash 已升级", userId)
+}
+
+func (u *UserService) GetUserByUsername(ctx context.Context, username string) (*vo.UserVO, result.AppError) {
+	user, err := u.userRepository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, result.NewAppError(constant.CodeRecordNotFound, "user not found")
+	}
+
+	return user.ToVO(), nil
+}
+
+// GetUserById 获取用户信息，被禁用的用户返回 CodeUserDisabled
+func (u *UserService) GetUserById(ctx context.Context, userId uint64) (*vo.UserVO, result.AppError) {
+	user, err := u.userRepository.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, result.NewAppError(constant.CodeRecordNotFound, "user not found")
+	}
+	if user.State == constant.UserStatusDisabled {
+		return nil, result.NewAppError(constant.CodeUserDisabled, "user is disabled")
+	}
+
+	return user.ToVO(), nil
+}
+
+// ListUsers 分页查询用户列表
+func (u *UserService) ListUsers(ctx context.Context, page *request.PageRequest) (*vo.PageVO[*vo.UserVO], result.AppError) {
+	users, total, err := u.userRepository.FindUsers(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*vo.UserVO, 0, len(users))
+	for _, user := range users {
+		items = append(items, user.ToVO())
+	}
+
+	return vo.NewPageVO(items, total, page.Page, page.Size), nil
+}
+
 var _ UserServiceInterface = (*UserService)(nil)
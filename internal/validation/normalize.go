@@ -0,0 +1,196 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeTag 控制字符串规范化的 struct tag。
+// 默认去掉前后空白；tag 为 "-" 时不做任何处理，例如密码。
+// 其他规则用逗号分隔，不论书写顺序都按下面的顺序执行：
+//
+//	notrim    不去掉前后空白
+//	nfc       转换为 Unicode NFC 形式
+//	collapse  连续的空白合并为一个空格
+//	lower     转换为小写
+//	upper     转换为大写
+//
+// 例如 Email string `json:"email" normalize:"lower"`。
+// 规则作用于字段本身的 string，以及 *string、[]string、map[string]string 等容器中的 string；
+// 嵌套的结构体使用自己字段上的 tag。
+const NormalizeTag = "normalize"
+
+type normalizeRules struct {
+	skip     bool
+	trim     bool
+	nfc      bool
+	collapse bool
+	lower    bool
+	upper    bool
+}
+
+var defaultRules = normalizeRules{trim: true}
+
+func parseNormalizeTag(tag string) (normalizeRules, error) {
+	rules := defaultRules
+	if tag == "" {
+		return rules, nil
+	}
+	if tag == "-" {
+		return normalizeRules{skip: true}, nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(rule) {
+		case "notrim":
+			rules.trim = false
+		case "nfc":
+			rules.nfc = true
+		case "collapse":
+			rules.collapse = true
+		case "lower":
+			rules.lower = true
+		case "upper":
+			rules.upper = true
+		default:
+			return rules, fmt.Errorf("不支持的 normalize 规则: %s", rule)
+		}
+	}
+	if rules.lower && rules.upper {
+		return rules, fmt.Errorf("normalize 规则 lower 和 upper 不能同时使用")
+	}
+
+	return rules, nil
+}
+
+func (r normalizeRules) apply(s string) string {
+	if r.nfc {
+		s = norm.NFC.String(s)
+	}
+	if r.collapse {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if r.trim {
+		s = strings.TrimSpace(s)
+	}
+	if r.lower {
+		s = strings.ToLower(s)
+	}
+	if r.upper {
+		s = strings.ToUpper(s)
+	}
+	return s
+}
+
+// Normalize 按 normalize tag 规范化 v 中的字符串，v 需要是指针。
+// 会递归处理嵌套的结构体、指针、slice、数组、map 和 interface 中的值，只处理导出的字段。
+// 需要在校验之前调用，这样只有空白的字符串不能通过 required 校验。
+func Normalize(v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("Normalize 需要非 nil 的指针，实际是 %T", v)
+	}
+
+	n := &normalizer{visited: map[uintptr]bool{}}
+	_, err := n.normalize(value, defaultRules)
+	return err
+}
+
+type normalizer struct {
+	// visited 已经处理过的指针，避免循环引用时无限递归
+	visited map[uintptr]bool
+}
+
+// normalize 规范化 value，value 不可寻址（例如 map 中的值）时返回修改后的副本，由调用方写回
+func (n *normalizer) normalize(value reflect.Value, rules normalizeRules) (reflect.Value, error) {
+	if rules.skip {
+		return value, nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		normalized := reflect.New(value.Type()).Elem()
+		normalized.SetString(rules.apply(value.String()))
+		if value.CanSet() {
+			value.Set(normalized)
+		}
+		return normalized, nil
+
+	case reflect.Ptr:
+		if value.IsNil() || n.visited[value.Pointer()] {
+			return value, nil
+		}
+		n.visited[value.Pointer()] = true
+		_, err := n.normalize(value.Elem(), rules)
+		return value, err
+
+	case reflect.Interface:
+		if value.IsNil() {
+			return value, nil
+		}
+		normalized, err := n.normalize(value.Elem(), rules)
+		if err != nil {
+			return value, err
+		}
+		if value.CanSet() {
+			value.Set(normalized)
+		}
+		return normalized, nil
+
+	case reflect.Struct:
+		if !value.CanSet() {
+			copied := reflect.New(value.Type()).Elem()
+			copied.Set(value)
+			value = copied
+		}
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldRules, err := parseNormalizeTag(field.Tag.Get(NormalizeTag))
+			if err != nil {
+				return value, fmt.Errorf("%s.%s: %w", value.Type().Name(), field.Name, err)
+			}
+			if _, err := n.normalize(value.Field(i), fieldRules); err != nil {
+				return value, err
+			}
+		}
+		return value, nil
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return value, nil
+		}
+		if value.Kind() == reflect.Array && !value.CanSet() {
+			copied := reflect.New(value.Type()).Elem()
+			copied.Set(value)
+			value = copied
+		}
+		for i := 0; i < value.Len(); i++ {
+			if _, err := n.normalize(value.Index(i), rules); err != nil {
+				return value, err
+			}
+		}
+		return value, nil
+
+	case reflect.Map:
+		if value.IsNil() {
+			return value, nil
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			normalized, err := n.normalize(iter.Value(), rules)
+			if err != nil {
+				return value, err
+			}
+			value.SetMapIndex(iter.Key(), normalized)
+		}
+		return value, nil
+	}
+
+	return value, nil
+}
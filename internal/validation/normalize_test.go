@@ -0,0 +1,174 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeRules(t *testing.T) {
+	type request struct {
+		Default  string
+		Skip     string `normalize:"-"`
+		NoTrim   string `normalize:"notrim"`
+		NFC      string `normalize:"nfc"`
+		Collapse string `normalize:"collapse"`
+		Lower    string `normalize:"lower"`
+		Upper    string `normalize:"upper,collapse"`
+	}
+	req := &request{
+		Default:  "  bob  ",
+		Skip:     "  secret  ",
+		NoTrim:   "  keep  ",
+		NFC:      " e\u0301 ",
+		Collapse: "  a \t b\n c  ",
+		Lower:    " Bob@Example.COM ",
+		Upper:    " a  b ",
+	}
+	if err := Normalize(req); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	want := request{
+		Default:  "bob",
+		Skip:     "  secret  ",
+		NoTrim:   "  keep  ",
+		NFC:      "\u00e9",
+		Collapse: "a b c",
+		Lower:    "bob@example.com",
+		Upper:    "A B",
+	}
+	if *req != want {
+		t.Fatalf("got %+v, want %+v", *req, want)
+	}
+}
+
+func TestNormalizeNested(t *testing.T) {
+	type address struct {
+		City string `normalize:"upper"`
+	}
+	type request struct {
+		Name      *string `normalize:"lower"`
+		Tags      []string
+		Codes     [2]string `normalize:"lower"`
+		Addresses []address
+		Primary   *address
+		Labels    map[string]string `normalize:"lower"`
+		Extra     map[string]address
+		Anything  any
+		Skipped   []string `normalize:"-"`
+		private   string
+	}
+	name := " BOB "
+	req := &request{
+		Name:      &name,
+		Tags:      []string{" a ", "b "},
+		Codes:     [2]string{" X ", "Y"},
+		Addresses: []address{{City: " paris "}},
+		Primary:   &address{City: " rome "},
+		Labels:    map[string]string{" Key ": " VALUE "},
+		Extra:     map[string]address{"home": {City: " oslo "}},
+		Anything:  " boxed ",
+		Skipped:   []string{" raw "},
+		private:   " private ",
+	}
+	if err := Normalize(req); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	want := request{
+		Name:      &name,
+		Tags:      []string{"a", "b"},
+		Codes:     [2]string{"x", "y"},
+		Addresses: []address{{City: "PARIS"}},
+		Primary:   &address{City: "ROME"},
+		Labels:    map[string]string{" Key ": "value"},
+		Extra:     map[string]address{"home": {City: "OSLO"}},
+		Anything:  "boxed",
+		Skipped:   []string{" raw "},
+		private:   " private ",
+	}
+	if name != "bob" {
+		t.Errorf("Name = %q, want %q", name, "bob")
+	}
+	if !reflect.DeepEqual(*req, want) {
+		t.Fatalf("got %+v, want %+v", *req, want)
+	}
+}
+
+func TestNormalizeInterface(t *testing.T) {
+	type inner struct {
+		Value string `normalize:"lower"`
+	}
+	type request struct {
+		Struct  any
+		Pointer any
+		Map     any
+		Nil     any
+	}
+	pointer := &inner{Value: " PTR "}
+	req := &request{
+		Struct:  inner{Value: " STRUCT "},
+		Pointer: pointer,
+		Map:     map[string]any{"k": " v ", "n": 1},
+	}
+	if err := Normalize(req); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+
+	if got := req.Struct.(inner).Value; got != "struct" {
+		t.Errorf("Struct.Value = %q, want %q", got, "struct")
+	}
+	if pointer.Value != "ptr" {
+		t.Errorf("Pointer.Value = %q, want %q", pointer.Value, "ptr")
+	}
+	if got := req.Map.(map[string]any); got["k"] != "v" || got["n"] != 1 {
+		t.Errorf("Map = %v, want map[k:v n:1]", got)
+	}
+	if req.Nil != nil {
+		t.Errorf("Nil = %v, want nil", req.Nil)
+	}
+}
+
+func TestNormalizeCycle(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	a := &node{Name: " a "}
+	b := &node{Name: " b ", Next: a}
+	a.Next = b
+
+	if err := Normalize(a); err != nil {
+		t.Fatalf("Normalize: %v", err)
+	}
+	if a.Name != "a" || b.Name != "b" {
+		t.Fatalf("names = %q, %q; want a, b", a.Name, b.Name)
+	}
+}
+
+func TestNormalizeErrors(t *testing.T) {
+	type badRule struct {
+		Value string `normalize:"shout"`
+	}
+	type conflicting struct {
+		Value string `normalize:"lower,upper"`
+	}
+	var nilPointer *badRule
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"not a pointer", badRule{}},
+		{"nil pointer", nilPointer},
+		{"unknown rule", &badRule{}},
+		{"lower and upper", &conflicting{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Normalize(tt.v); err == nil {
+				t.Fatal("want error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// 支持的语言，DefaultLocale 在 Accept-Language 中没有支持的语言时使用
+const (
+	LocaleEn      = "en"
+	LocaleZh      = "zh"
+	DefaultLocale = LocaleEn
+)
+
+// FieldError 单个字段的校验错误，返回给前端用于在表单中定位字段
+type FieldError struct {
+	Field   string `json:"field"` // json 字段名，嵌套字段用 . 分隔，例如 address.city、items[0].name
+	Rule    string `json:"rule"`  // validate tag 中的规则，例如 required、email
+	Param   string `json:"param"` // 规则的参数，例如 min=3 中的 3
+	Message string `json:"message"`
+}
+
+// Validator 在 validator.Validate 的基础上，把校验错误转换为按 Accept-Language 翻译的 FieldError
+type Validator struct {
+	*validator.Validate
+	uni *ut.UniversalTranslator
+}
+
+// fieldNameTags 错误中的字段名依次从这些 tag 中获取，都没有时使用结构体字段名
+var fieldNameTags = []string{"json", "form", "query", "params", "header", "cookie"}
+
+// New 创建 Validator，错误中的字段名使用 json 等 tag 中的名称，并注册中英文翻译
+func New() (*Validator, error) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range fieldNameTags {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			switch name {
+			case "-":
+				return ""
+			case "":
+				continue
+			}
+			return name
+		}
+		return field.Name
+	})
+
+	enLocale := en.New()
+	uni := ut.New(enLocale, enLocale, zh.New())
+	enTrans, _ := uni.GetTranslator(LocaleEn)
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, fmt.Errorf("注册英文校验翻译失败: %w", err)
+	}
+	zhTrans, _ := uni.GetTranslator(LocaleZh)
+	if err := zhTranslations.RegisterDefaultTranslations(validate, zhTrans); err != nil {
+		return nil, fmt.Errorf("注册中文校验翻译失败: %w", err)
+	}
+
+	return &Validator{Validate: validate, uni: uni}, nil
+}
+
+// Translate 把 validator.ValidationErrors 转换为 FieldError 列表，err 不是校验错误时返回 nil
+func (v *Validator) Translate(err error, locale string) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	trans, _ := v.uni.GetTranslator(locale)
+	fieldErrors := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+
+	return fieldErrors
+}
+
+// fieldPath 去掉 Namespace 中最外层的结构体名称，例如 RegisterRequest.email 转换为 email
+func fieldPath(namespace string) string {
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+	return namespace
+}
+
+// Locale 按 Accept-Language 中的顺序选择第一个支持的语言，例如 zh-CN,zh;q=0.9,en;q=0.8 选择 zh
+func Locale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		switch primary {
+		case LocaleZh:
+			return LocaleZh
+		case LocaleEn:
+			return LocaleEn
+		}
+	}
+	return DefaultLocale
+}
+
+// typeErrorMessages 参数类型转换失败时的提示
+var typeErrorMessages = map[string]string{
+	LocaleEn: "%s must be a valid %s",
+	LocaleZh: "%s必须是有效的%s",
+}
+
+// TypeError 绑定请求参数时类型转换失败的错误，Rule 固定为 type，Param 是期望的类型
+func TypeError(field, typeName, locale string) FieldError {
+	format, ok := typeErrorMessages[locale]
+	if !ok {
+		format = typeErrorMessages[DefaultLocale]
+	}
+	return FieldError{
+		Field:   field,
+		Rule:    "type",
+		Param:   typeName,
+		Message: fmt.Sprintf(format, field, typeName),
+	}
+}
@@ -0,0 +1,72 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// 以下变量在构建时通过 ldflags 注入，参考 build.sh
+var (
+	AppName          = "my-web-template"
+	AppVersion       = "dev"
+	BuiltAt          = ""
+	BuiltAtTimestamp = ""
+	GoVersion        = ""
+	GitAuthor        = ""
+	GitCommit        = ""
+)
+
+// BuildInfo 构建信息，用于 version 子命令和 build-info 接口。
+// GitAuthor 只输出到日志和 version 子命令，不会出现在公开的 /version 接口中
+type BuildInfo struct {
+	AppName          string `json:"app_name"`
+	AppVersion       string `json:"app_version"`
+	BuiltAt          string `json:"built_at"`
+	BuiltAtTimestamp string `json:"built_at_timestamp"`
+	GoVersion        string `json:"go_version"`
+	GitAuthor        string `json:"-"`
+	GitCommit        string `json:"git_commit"`
+}
+
+// Info 返回构建信息。没有通过 ldflags 注入时（例如 go run），尽量从 Go 自带的构建信息中补全
+func Info() BuildInfo {
+	info := BuildInfo{
+		AppName:          AppName,
+		AppVersion:       AppVersion,
+		BuiltAt:          BuiltAt,
+		BuiltAtTimestamp: BuiltAtTimestamp,
+		GoVersion:        GoVersion,
+		GitAuthor:        GitAuthor,
+		GitCommit:        GitCommit,
+	}
+
+	if info.GoVersion == "" {
+		info.GoVersion = runtime.Version()
+	}
+	if info.GitCommit == "" {
+		if buildInfo, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range buildInfo.Settings {
+				if setting.Key == "vcs.revision" {
+					info.GitCommit = setting.Value
+				}
+			}
+		}
+	}
+
+	return info
+}
+
+// String 单行的版本描述，例如 my-web-template 1.0.0 (commit abc123, built at 2025-01-01 00:00:00 +0800, go1.24)
+func String() string {
+	info := Info()
+	builtAt := info.BuiltAt
+	if builtAt == "" {
+		builtAt = "unknown"
+	}
+	commit := info.GitCommit
+	if commit == "" {
+		commit = "unknown"
+	}
+	return fmt.Sprintf("%s %s (commit %s, built at %s, %s)", info.AppName, info.AppVersion, commit, builtAt, info.GoVersion)
+}
@@ -1,70 +1,119 @@
 package controller
 
 import (
-	"reflect"
 	"strings"
 
-	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/session"
+	"go.uber.org/zap"
 	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
+	"my-web-template/internal/logging"
+	"my-web-template/internal/metrics"
 	"my-web-template/internal/result"
+	"my-web-template/internal/validation"
+	"my-web-template/internal/web/middleware"
 )
 
+// AppBaseController controller 共用的请求解析和 session 操作。
+// handler 出错时直接返回 result.AppError，由全局 ErrorHandler 渲染成 AppResult 并设置对应的 HTTP 状态码。
 type AppBaseController struct {
-	validator    *validator.Validate
+	validator    *validation.Validator
 	sessionStore *session.Store
 }
 
-func NewAppBaseController(v *validator.Validate, s *session.Store) *AppBaseController {
+func NewAppBaseController(v *validation.Validator, s *session.Store) *AppBaseController {
 	return &AppBaseController{
 		validator:    v,
 		sessionStore: s,
 	}
 }
 
-func (c *AppBaseController) parseAndValidateBody(ctx *fiber.Ctx, request interface{}) result.AppError {
-	if err := ctx.BodyParser(request); err != nil {
-		return result.NewAppErrorFromError(constant.CodeParamError, err)
+// parsePageQuery 按 spec 解析列表接口的分页、排序和过滤参数，参数不合法时返回 CodeParamError
+func (c *AppBaseController) parsePageQuery(ctx *fiber.Ctx, spec request.PageSpec) (*request.PageRequest, result.AppError) {
+	page, err := request.ParsePageRequest(ctx.Queries(), spec)
+	if err != nil {
+		return nil, result.NewAppErrorFromError(constant.CodeParamError, err)
+	}
+
+	return page, nil
+}
+
+// validate 校验请求参数，校验失败时按 Accept-Language 翻译错误信息，字段列表放在 AppResult.data 中
+func (c *AppBaseController) validate(ctx *fiber.Ctx, request interface{}) result.AppError {
+	err := c.validator.Struct(request)
+	if err == nil {
+		return nil
 	}
-	if err := c.validator.Struct(request); err != nil {
+
+	fieldErrors := c.validator.Translate(err, validation.Locale(ctx.Get(fiber.HeaderAcceptLanguage)))
+	if len(fieldErrors) == 0 {
 		return result.NewAppErrorFromError(constant.CodeParamError, err)
 	}
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		messages = append(messages, fieldError.Message)
+	}
 
-	c.trimStringField(request)
+	return result.NewAppErrorWithData(constant.CodeParamError, strings.Join(messages, "; "), fieldErrors)
+}
+
+// loginSession 登录成功后调用，重新生成 session ID 防止 session fixation，并记录当前用户。
+// 使用 Reset 而不是 Regenerate，确保旧 session 中缓存的数据（例如权限）不会带到新用户上。
+func (c *AppBaseController) loginSession(ctx *fiber.Ctx, userId uint64) result.AppError {
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+	if err := sess.Reset(); err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+	sess.Set(constant.SessionKeyUserId, userId)
+	if err := sess.Save(); err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+	metrics.ObserveSessionEvent(metrics.SessionEventLogin)
 
 	return nil
 }
 
-func (c *AppBaseController) parseAndValidateQuery(ctx *fiber.Ctx, request interface{}) result.AppError {
-	if err := ctx.QueryParser(request); err != nil {
-		return result.NewAppErrorFromError(constant.CodeParamError, err)
+// logoutSession 销毁当前 session
+func (c *AppBaseController) logoutSession(ctx *fiber.Ctx) result.AppError {
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
 	}
-	if err := c.validator.Struct(request); err != nil {
-		return result.NewAppErrorFromError(constant.CodeParamError, err)
+	if err := sess.Destroy(); err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
 	}
-
-	c.trimStringField(request)
+	metrics.ObserveSessionEvent(metrics.SessionEventLogout)
 
 	return nil
 }
 
-// trimStringField 通过反射，将结构体中的 string 字段去掉前后空格
-func (c *AppBaseController) trimStringField(request interface{}) {
-	v := reflect.ValueOf(request)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
+// getCurrentUserId 获取当前登录用户的 ID，未登录时返回 CodeNotLogin
+func (c *AppBaseController) getCurrentUserId(ctx *fiber.Ctx) (uint64, result.AppError) {
+	sess, err := c.sessionStore.Get(ctx)
+	if err != nil {
+		return 0, result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
 	}
-	if v.Kind() != reflect.Struct {
-		return
+	userId, ok := sess.Get(constant.SessionKeyUserId).(uint64)
+	if !ok || userId == 0 {
+		return 0, result.NewAppError(constant.CodeNotLogin, "not logged in")
 	}
 
-	for i := 0; i < v.NumField(); i++ {
-		field := v.Field(i)
-		if field.Kind() == reflect.String {
-			if field.CanSet() {
-				field.SetString(strings.TrimSpace(field.String()))
-			}
-		}
-	}
+	return userId, nil
+}
+
+// success 返回成功的 AppResult，并带上当前请求的 request id
+func (c *AppBaseController) success(ctx *fiber.Ctx, data any) error {
+	appResult := result.NewSuccessResult(data)
+	appResult.RequestId = middleware.GetRequestID(ctx)
+	ctx.Locals(constant.LocalsKeyResultCode, appResult.Code)
+	return ctx.JSON(appResult)
+}
+
+// requestLogger 获取带有 request_id 字段的请求级 logger
+func (c *AppBaseController) requestLogger(ctx *fiber.Ctx) *zap.SugaredLogger {
+	return middleware.GetLogger(ctx, logging.Sugar())
 }
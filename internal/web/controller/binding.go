@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/result"
+	"my-web-template/internal/validation"
+)
+
+// 绑定请求参数使用的 struct tag，body 使用 BodyParser 的 json、xml、form tag
+const (
+	bindTagParams = "params"
+	bindTagQuery  = "query"
+	bindTagHeader = "header"
+	bindTagCookie = "cookie"
+	// bindTagForm multipart 请求中的文件，字段类型需要是 *multipart.FileHeader 或 []*multipart.FileHeader
+	bindTagForm = "form"
+	bindTagJSON = "json"
+	bindTagXML  = "xml"
+)
+
+var (
+	sourceTags = []string{bindTagParams, bindTagQuery, bindTagHeader, bindTagCookie}
+	bodyTags   = []string{bindTagJSON, bindTagXML, bindTagForm}
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// parseAndValidate 从多个来源填充 request 后规范化并校验，request 需要是结构体指针：
+//
+//	type UpdateUserRequest struct {
+//		UserId  uint64                `params:"id" validate:"required"`
+//		Version int                   `query:"version"`
+//		Token   string                `header:"X-Token"`
+//		Name    string                `json:"name" form:"name" validate:"required"`
+//		Avatar  *multipart.FileHeader `form:"avatar"`
+//	}
+//
+// 请求有 body 时先按 Content-Type 使用 BodyParser 解析 JSON、XML、urlencoded、multipart 表单，
+// 再按 params、query、header、cookie tag 填充对应字段，只有声明了 tag 的字段会被这些来源修改，
+// 同时声明了 body tag 的字段两边都有值时以这些来源为准。
+// 只声明了这些 tag、没有 json、xml、form tag 的字段不能通过 body 设置，BodyParser 写入的值会被清空。
+// 类型转换失败和校验失败都返回 CodeParamError，AppResult.data 中是 validation.FieldError 列表；
+// body 或 multipart 表单解析失败同样返回 CodeParamError，但没有字段列表。
+func (c *AppBaseController) parseAndValidate(ctx *fiber.Ctx, request interface{}) result.AppError {
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			return result.NewAppErrorFromError(constant.CodeParamError, err)
+		}
+	}
+
+	value := reflect.ValueOf(request)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		return result.NewAppError(constant.CodeRuntimeError, "parseAndValidate 需要结构体指针", true)
+	}
+	binder := &requestBinder{ctx: ctx, locale: validation.Locale(ctx.Get(fiber.HeaderAcceptLanguage))}
+	binder.bindStruct(value.Elem())
+	if binder.err != nil {
+		return result.NewAppErrorFromError(constant.CodeParamError, binder.err)
+	}
+	if len(binder.errors) > 0 {
+		return result.NewAppErrorWithData(constant.CodeParamError, binder.errors[0].Message, binder.errors)
+	}
+
+	if err := validation.Normalize(request); err != nil {
+		return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+	return c.validate(ctx, request)
+}
+
+// requestBinder 把 path 参数、query、header、cookie 和上传的文件写入结构体字段
+type requestBinder struct {
+	ctx    *fiber.Ctx
+	locale string
+	errors []validation.FieldError
+	// err 不属于某个字段的错误，例如 multipart 表单解析失败
+	err error
+}
+
+func (b *requestBinder) bindStruct(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			b.bindStruct(fieldValue)
+			continue
+		}
+
+		if name := field.Tag.Get(bindTagForm); name != "" && (field.Type == fileHeaderType || field.Type == fileHeaderSliceType) {
+			b.bindFiles(fieldValue, name)
+			continue
+		}
+		if hasTag(field, sourceTags) && !hasTag(field, bodyTags) {
+			// BodyParser 会按字段名匹配没有 tag 的字段，例如 body 中的 userid 会写入 UserId
+			fieldValue.Set(reflect.Zero(field.Type))
+		}
+		for _, tag := range sourceTags {
+			name := field.Tag.Get(tag)
+			if name == "" || name == "-" {
+				continue
+			}
+			values := b.lookup(tag, name)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setField(fieldValue, values); err != nil {
+				b.errors = append(b.errors, validation.TypeError(name, typeName(field.Type), b.locale))
+			}
+		}
+	}
+}
+
+// hasTag 字段是否声明了 tags 中任意一个 tag，值为 "-" 时视为没有声明
+func hasTag(field reflect.StructField, tags []string) bool {
+	for _, tag := range tags {
+		if name := field.Tag.Get(tag); name != "" && name != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup 获取参数的值，query 和 header 可能有多个值，其他来源最多一个
+func (b *requestBinder) lookup(tag, name string) []string {
+	var values []string
+	switch tag {
+	case bindTagParams:
+		if v := b.ctx.Params(name); v != "" {
+			values = append(values, v)
+		}
+	case bindTagQuery:
+		for _, v := range b.ctx.Context().QueryArgs().PeekMulti(name) {
+			values = append(values, string(v))
+		}
+	case bindTagHeader:
+		for _, v := range b.ctx.Request().Header.PeekAll(name) {
+			values = append(values, string(v))
+		}
+	case bindTagCookie:
+		if v := b.ctx.Cookies(name); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func (b *requestBinder) bindFiles(value reflect.Value, name string) {
+	if !strings.HasPrefix(b.ctx.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return
+	}
+	form, err := b.ctx.MultipartForm()
+	if err != nil {
+		b.err = err
+		return
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return
+	}
+	if value.Type() == fileHeaderType {
+		value.Set(reflect.ValueOf(files[0]))
+	} else {
+		value.Set(reflect.ValueOf(files))
+	}
+}
+
+// setField 把字符串转换为字段的类型，支持基本类型、指针和 slice；slice 的值也可以用逗号分隔
+func setField(value reflect.Value, values []string) error {
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		if err := setField(elem.Elem(), values); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	case reflect.Slice:
+		var parts []string
+		for _, v := range values {
+			parts = append(parts, strings.Split(v, ",")...)
+		}
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setField(slice.Index(i), []string{part}); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+
+	raw := values[0]
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(v)
+	default:
+		return strconv.ErrSyntax
+	}
+	return nil
+}
+
+// typeName 类型错误中展示的类型名称，指针和 slice 使用元素的类型
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind().String()
+}
@@ -0,0 +1,223 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/validation"
+)
+
+type bindingRequest struct {
+	UserId  uint64   `params:"id" validate:"required"`
+	Version int      `query:"version"`
+	Ids     []int    `query:"ids"`
+	Token   string   `header:"X-Token"`
+	Session string   `cookie:"sid"`
+	Page    int      `json:"page" query:"page"`
+	Name    string   `json:"name" validate:"required"`
+	Tags    []string `json:"tags"`
+}
+
+// bindingResponse 解析结果，失败时是 AppResult
+type bindingResponse struct {
+	Code constant.ResultCode     `json:"code"`
+	Data []validation.FieldError `json:"data"`
+	Req  bindingRequest          `json:"req"`
+}
+
+func newTestController(t *testing.T) *AppBaseController {
+	t.Helper()
+	v, err := validation.New()
+	if err != nil {
+		t.Fatalf("validation.New: %v", err)
+	}
+	return NewAppBaseController(v, nil)
+}
+
+// doBind 用 parseAndValidate 解析发往 /users/:id 的请求
+func doBind(t *testing.T, req *http.Request) bindingResponse {
+	t.Helper()
+	base := newTestController(t)
+	app := fiber.New()
+	app.Post("/users/:id", func(ctx *fiber.Ctx) error {
+		var parsed bindingRequest
+		if err := base.parseAndValidate(ctx, &parsed); err != nil {
+			return ctx.JSON(err.ToAppResult())
+		}
+		return ctx.JSON(fiber.Map{"code": constant.CodeSuccess, "req": parsed})
+	})
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var out bindingResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("decode %s: %v", body, err)
+	}
+	return out
+}
+
+func jsonRequest(target, body string) *http.Request {
+	req := httptest.NewRequest(fiber.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return req
+}
+
+func TestParseAndValidateSources(t *testing.T) {
+	req := jsonRequest("/users/7?version=3&ids=1,2&ids=3", `{"name":"bob","tags":["a"]}`)
+	req.Header.Set("X-Token", "token")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "session"})
+
+	out := doBind(t, req)
+	if out.Code != constant.CodeSuccess {
+		t.Fatalf("code = %d, data = %+v", out.Code, out.Data)
+	}
+	want := bindingRequest{
+		UserId:  7,
+		Version: 3,
+		Ids:     []int{1, 2, 3},
+		Token:   "token",
+		Session: "session",
+		Name:    "bob",
+		Tags:    []string{"a"},
+	}
+	if !reflect.DeepEqual(out.Req, want) {
+		t.Fatalf("got %+v, want %+v", out.Req, want)
+	}
+}
+
+func TestParseAndValidatePrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		body   string
+		header string
+		want   bindingRequest
+	}{
+		{
+			name:   "query overrides body for fields with both tags",
+			target: "/users/7?page=2",
+			body:   `{"name":"bob","page":1}`,
+			want:   bindingRequest{UserId: 7, Page: 2, Name: "bob"},
+		},
+		{
+			name:   "body is used when query is absent",
+			target: "/users/7",
+			body:   `{"name":"bob","page":1}`,
+			want:   bindingRequest{UserId: 7, Page: 1, Name: "bob"},
+		},
+		{
+			name:   "body cannot set a path parameter",
+			target: "/users/7",
+			body:   `{"name":"bob","UserId":99,"userid":99}`,
+			want:   bindingRequest{UserId: 7, Name: "bob"},
+		},
+		{
+			name:   "body cannot set query, header or cookie fields",
+			target: "/users/7",
+			body:   `{"name":"bob","Version":5,"Ids":[9],"Token":"forged","Session":"forged"}`,
+			want:   bindingRequest{UserId: 7, Name: "bob"},
+		},
+		{
+			name:   "header wins over a forged body value",
+			target: "/users/7",
+			body:   `{"name":"bob","Token":"forged"}`,
+			header: "real",
+			want:   bindingRequest{UserId: 7, Token: "real", Name: "bob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := jsonRequest(tt.target, tt.body)
+			if tt.header != "" {
+				req.Header.Set("X-Token", tt.header)
+			}
+			out := doBind(t, req)
+			if out.Code != constant.CodeSuccess {
+				t.Fatalf("code = %d, data = %+v", out.Code, out.Data)
+			}
+			if !reflect.DeepEqual(out.Req, tt.want) {
+				t.Fatalf("got %+v, want %+v", out.Req, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAndValidateErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		body      string
+		wantField string
+		wantRule  string
+		wantParam string
+	}{
+		{"int query", "/users/7?version=abc", `{"name":"bob"}`, "version", "type", "int"},
+		{"uint path", "/users/abc", `{"name":"bob"}`, "id", "type", "uint64"},
+		{"negative uint path", "/users/-1", `{"name":"bob"}`, "id", "type", "uint64"},
+		{"int slice element", "/users/7?ids=1,x", `{"name":"bob"}`, "ids", "type", "int"},
+		{"required after binding", "/users/7", `{"name":"  "}`, "name", "required", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := doBind(t, jsonRequest(tt.target, tt.body))
+			if out.Code != constant.CodeParamError {
+				t.Fatalf("code = %d, want %d", out.Code, constant.CodeParamError)
+			}
+			if len(out.Data) != 1 {
+				t.Fatalf("data = %+v, want exactly one field error", out.Data)
+			}
+			got := out.Data[0]
+			if got.Field != tt.wantField || got.Rule != tt.wantRule || got.Param != tt.wantParam {
+				t.Fatalf("field error = %+v, want field %s rule %s param %s", got, tt.wantField, tt.wantRule, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestParseAndValidateMalformedBody(t *testing.T) {
+	out := doBind(t, jsonRequest("/users/7", `{"name":`))
+	if out.Code != constant.CodeParamError {
+		t.Fatalf("code = %d, want %d", out.Code, constant.CodeParamError)
+	}
+}
+
+func TestParseAndValidateMultipartError(t *testing.T) {
+	type uploadRequest struct {
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+	base := newTestController(t)
+	app := fiber.New()
+	app.Post("/upload", func(ctx *fiber.Ctx) error {
+		var parsed uploadRequest
+		if err := base.parseAndValidate(ctx, &parsed); err != nil {
+			return ctx.JSON(err.ToAppResult())
+		}
+		return ctx.JSON(fiber.Map{"code": constant.CodeSuccess})
+	})
+
+	// body 为空时不会调用 BodyParser，multipart 表单在绑定文件时才解析
+	req := httptest.NewRequest(fiber.MethodPost, "/upload", nil)
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEMultipartForm+"; boundary=xyz")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	var out bindingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Code != constant.CodeParamError {
+		t.Fatalf("code = %d, want %d", out.Code, constant.CodeParamError)
+	}
+}
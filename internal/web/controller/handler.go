@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"my-web-template/internal/entity/request"
+	"my-web-template/internal/result"
+)
+
+// HandlerFunc 类型化的 handler，req 已经完成绑定、规范化和校验，返回的 Resp 作为 AppResult.data
+type HandlerFunc[Req any, Resp any] func(ctx *fiber.Ctx, req *Req) (Resp, result.AppError)
+
+// Empty 没有请求参数或没有响应数据的接口使用，返回 nil 时 AppResult.data 为 null
+type Empty struct{}
+
+// Endpoint 通过 Route 注册的接口，记录请求和响应的类型，可以用于生成接口文档和客户端
+type Endpoint struct {
+	Method   string
+	Path     string
+	Request  reflect.Type
+	Response reflect.Type
+}
+
+var endpoints struct {
+	sync.Mutex
+	list []Endpoint
+}
+
+// Endpoints 返回所有通过 Route 注册的接口，按路径和方法排序
+func Endpoints() []Endpoint {
+	endpoints.Lock()
+	defer endpoints.Unlock()
+
+	list := slices.Clone(endpoints.list)
+	slices.SortFunc(list, func(a, b Endpoint) int {
+		return cmp.Or(strings.Compare(a.Path, b.Path), strings.Compare(a.Method, b.Method))
+	})
+	return list
+}
+
+// Handle 把 HandlerFunc 转换为 fiber.Handler：
+// 创建 Req 并通过 bind 绑定和校验，调用 fn，成功时用 AppResult 包装返回值；
+// 出错时直接返回 AppError，由全局 ErrorHandler 渲染并设置对应的 HTTP 状态码。
+func Handle[Req any, Resp any](base *AppBaseController, fn HandlerFunc[Req, Resp]) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		req := new(Req)
+		if err := base.bind(ctx, req); err != nil {
+			return err
+		}
+
+		resp, err := fn(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		return base.success(ctx, resp)
+	}
+}
+
+// Route 注册接口并记录 Req、Resp 类型，middlewares 在 handler 之前执行，例如权限检查：
+//
+//	Route(userAPI, fiber.MethodGet, "/v1/info", u.base, u.GetUserInfoByUsername, requirePermission(constant.PermissionUserRead))
+func Route[Req any, Resp any](router fiber.Router, method, path string, base *AppBaseController, fn HandlerFunc[Req, Resp], middlewares ...fiber.Handler) {
+	handlers := append(slices.Clone(middlewares), Handle(base, fn))
+	router.Add(method, path, handlers...)
+
+	fullPath := path
+	if group, ok := router.(*fiber.Group); ok {
+		fullPath = strings.TrimSuffix(group.Prefix, "/") + path
+	}
+	endpoints.Lock()
+	defer endpoints.Unlock()
+	endpoints.list = append(endpoints.list, Endpoint{
+		Method:   method,
+		Path:     fullPath,
+		Request:  reflect.TypeFor[Req](),
+		Response: reflect.TypeFor[Resp](),
+	})
+}
+
+// bind 绑定请求参数。实现了 request.PageQuery 的列表请求再按 PageSpec 解析分页、排序和过滤参数，
+// 解析结果最后写入，body 等其他来源无法绕过 PageSpec 的白名单和分页大小限制。
+func (c *AppBaseController) bind(ctx *fiber.Ctx, req any) result.AppError {
+	if err := c.parseAndValidate(ctx, req); err != nil {
+		return err
+	}
+
+	if pageQuery, ok := req.(request.PageQuery); ok {
+		page, err := c.parsePageQuery(ctx, pageQuery.PageSpec())
+		if err != nil {
+			return err
+		}
+		pageQuery.SetPageRequest(page)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
+	"my-web-template/internal/result"
+)
+
+type listRequest struct {
+	request.PageRequest
+	Keyword string `json:"keyword"`
+}
+
+func (r *listRequest) PageSpec() request.PageSpec {
+	return request.PageSpec{
+		SortFields: []string{"username"},
+		FilterFields: map[string]request.FilterField{
+			"state": {Type: request.FieldInt, Ops: []request.FilterOp{request.FilterEq}},
+		},
+		MaxSize: 10,
+	}
+}
+
+// doList 通过 Handle 调用列表接口，返回 handler 收到的请求；绑定失败时返回错误码
+func doList(t *testing.T, target, body string) (*listRequest, constant.ResultCode) {
+	t.Helper()
+	var received *listRequest
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(ctx *fiber.Ctx, err error) error {
+			if appErr, ok := err.(result.AppError); ok {
+				return ctx.JSON(appErr.ToAppResult())
+			}
+			return err
+		},
+	})
+	app.Post("/list", Handle(newTestController(t), func(ctx *fiber.Ctx, req *listRequest) (*Empty, result.AppError) {
+		received = req
+		return nil, nil
+	}))
+
+	req := httptest.NewRequest(fiber.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	var out struct {
+		Code constant.ResultCode `json:"code"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("decode %s: %v", raw, err)
+	}
+	return received, out.Code
+}
+
+func TestBindPageRequestIgnoresBody(t *testing.T) {
+	bodies := []string{
+		`{"keyword":"bob","Page":3,"Size":1000,"Sorts":[{"Column":"password"}],"Filters":[{"Column":"1=1) OR (1","Op":"eq","Values":[1]}]}`,
+		`{"keyword":"bob","page":3,"size":1000,"sorts":[{"column":"password"}],"filters":[{"column":"password","op":"like","values":["%"]}]}`,
+		`{"keyword":"bob","PageRequest":{"Page":3,"Size":1000,"Sorts":[{"Column":"password"}]}}`,
+	}
+	want := request.PageRequest{
+		Page:  1,
+		Size:  10,
+		Sorts: []request.SortField{{Column: "id"}},
+	}
+	for _, body := range bodies {
+		req, code := doList(t, "/list", body)
+		if code != constant.CodeSuccess {
+			t.Fatalf("body %s: code = %d", body, code)
+		}
+		if req.Keyword != "bob" {
+			t.Errorf("body %s: keyword = %q, want bob", body, req.Keyword)
+		}
+		if !reflect.DeepEqual(req.PageRequest, want) {
+			t.Errorf("body %s: page = %+v, want %+v", body, req.PageRequest, want)
+		}
+	}
+}
+
+func TestBindPageRequestFromQuery(t *testing.T) {
+	req, code := doList(t, "/list?page=2&size=5&sort=-username&filter[state]=1", `{"Size":1000}`)
+	if code != constant.CodeSuccess {
+		t.Fatalf("code = %d", code)
+	}
+	want := request.PageRequest{
+		Page:    2,
+		Size:    5,
+		Sorts:   []request.SortField{{Column: "username", Desc: true}, {Column: "id"}},
+		Filters: []request.Filter{{Column: "state", Op: request.FilterEq, Values: []any{int64(1)}}},
+	}
+	if !reflect.DeepEqual(req.PageRequest, want) {
+		t.Fatalf("page = %+v, want %+v", req.PageRequest, want)
+	}
+}
+
+func TestBindPageRequestRejectsInvalidQuery(t *testing.T) {
+	for _, target := range []string{"/list?size=11", "/list?sort=password", "/list?filter[password]=x"} {
+		req, code := doList(t, target, `{}`)
+		if code != constant.CodeParamError || req != nil {
+			t.Errorf("%s: code = %d, handler called = %v; want %d and not called", target, code, req != nil, constant.CodeParamError)
+		}
+	}
+}
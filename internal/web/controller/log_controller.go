@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/entity/request"
+	"my-web-template/internal/logging"
+	"my-web-template/internal/result"
+	"my-web-template/internal/web/middleware"
+)
+
+// LogController 运行时查看和修改日志级别
+type LogController struct {
+	base   *AppBaseController
+	logger *zap.SugaredLogger
+}
+
+func NewLogController(logger *zap.SugaredLogger, base *AppBaseController) *LogController {
+	return &LogController{
+		logger: logger,
+		base:   base,
+	}
+}
+
+func (l *LogController) GetLevels(_ *fiber.Ctx, _ *Empty) (logging.LevelSnapshot, result.AppError) {
+	return logging.Levels(), nil
+}
+
+func (l *LogController) SetLevel(ctx *fiber.Ctx, req *request.SetLogLevelRequest) (logging.LevelSnapshot, result.AppError) {
+	userId, _ := l.base.getCurrentUserId(ctx)
+	l.base.requestLogger(ctx).Infof("用户 %d 修改日志级别: name=%q level=%q ttl=%q", userId, req.Name, req.Level, req.TTL)
+
+	levelRequest := logging.LevelRequest{Name: req.Name, Level: req.Level, TTL: req.TTL}
+	if err := levelRequest.Apply(); err != nil {
+		return logging.LevelSnapshot{}, result.NewAppErrorFromError(constant.CodeParamError, err)
+	}
+
+	return logging.Levels(), nil
+}
+
+func (l *LogController) SetupRouter(router fiber.Router, requirePermission middleware.RequirePermission) {
+	logAPI := router.Group("/admin/v1/log", requirePermission(constant.PermissionLogLevel))
+	Route(logAPI, fiber.MethodGet, "/level", l.base, l.GetLevels)
+	Route(logAPI, fiber.MethodPut, "/level", l.base, l.SetLevel)
+}
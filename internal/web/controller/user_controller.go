@@ -3,10 +3,13 @@ package controller
 import (
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
+	"my-web-template/internal/constant"
 	"my-web-template/internal/core/appcontext"
 	"my-web-template/internal/entity/request"
+	"my-web-template/internal/entity/vo"
 	"my-web-template/internal/result"
 	"my-web-template/internal/service"
+	"my-web-template/internal/web/middleware"
 )
 
 type UserController struct {
@@ -23,40 +26,69 @@ func NewUserController(logger *zap.SugaredLogger, base *AppBaseController, userS
 	}
 }
 
-func (u *UserController) Register(ctx *fiber.Ctx) error {
-	query := &request.RegisterRequest{}
-	if err := u.base.parseAndValidateBody(ctx, query); err != nil {
-		return ctx.JSON(err.ToAppResult())
-	}
+func (u *UserController) Register(ctx *fiber.Ctx, req *request.RegisterRequest) (*vo.UserVO, result.AppError) {
+	return u.userService.Register(ctx.UserContext(), req.Username, req.Email, req.Password)
+}
+
+func (u *UserController) GetUserInfoByUsername(ctx *fiber.Ctx, req *request.GetUserInfoRequest) (*vo.UserVO, result.AppError) {
+	// 测试获取全局变量和当前类中的日志
+	appcontext.Get().Logger.Infof("GetUserInfoByUsername: %s", req.Username)
+	u.logger.Infof("GetUserInfoByUsername: %s", req.Username)
+	// 请求级 logger，日志中会带上 request_id
+	u.base.requestLogger(ctx).Infof("GetUserInfoByUsername: %s", req.Username)
+
+	return u.userService.GetUserByUsername(ctx.UserContext(), req.Username)
+}
+
+// ListUsers 分页查询用户，参数格式见 request.ParsePageRequest，允许的字段见 request.UserListSpec
+func (u *UserController) ListUsers(ctx *fiber.Ctx, req *request.UserListRequest) (*vo.PageVO[*vo.UserVO], result.AppError) {
+	return u.userService.ListUsers(ctx.UserContext(), &req.PageRequest)
+}
 
-	user, err := u.userService.SaveUser(query.Username, query.Email, query.Password)
+func (u *UserController) Login(ctx *fiber.Ctx, req *request.LoginRequest) (*vo.UserVO, result.AppError) {
+	user, err := u.userService.Authenticate(ctx.UserContext(), req.Account, req.Password)
 	if err != nil {
-		return ctx.JSON(err.ToAppResult())
+		return nil, err
 	}
 
-	return ctx.JSON(result.NewSuccessResult(user))
+	if err := u.base.loginSession(ctx, user.UserId); err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
-func (u *UserController) GetUserInfoByUsername(ctx *fiber.Ctx) error {
-	query := &request.GetUserInfoRequest{}
-	if err := u.base.parseAndValidateQuery(ctx, query); err != nil {
-		return ctx.JSON(err.ToAppResult())
-	}
+func (u *UserController) Logout(ctx *fiber.Ctx, _ *Empty) (*Empty, result.AppError) {
+	return nil, u.base.logoutSession(ctx)
+}
 
-	// 测试获取全局变量和当前类中的日志
-	appcontext.Get().Logger.Infof("GetUserInfoByUsername: %s", query.Username)
-	u.logger.Infof("GetUserInfoByUsername: %s", query.Username)
+// CurrentUser 返回当前登录的用户；用户已被删除或禁用时同时清理 session，
+// 数据库错误等临时故障不会让用户掉线
+func (u *UserController) CurrentUser(ctx *fiber.Ctx, _ *Empty) (*vo.UserVO, result.AppError) {
+	userId, err := u.base.getCurrentUserId(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	user, err := u.userService.GetUserByUsername(query.Username)
+	user, err := u.userService.GetUserById(ctx.UserContext(), userId)
 	if err != nil {
-		return ctx.JSON(err.ToAppResult())
+		if code := err.ToAppResult().Code; code == constant.CodeRecordNotFound || code == constant.CodeUserDisabled {
+			if logoutErr := u.base.logoutSession(ctx); logoutErr != nil {
+				u.base.requestLogger(ctx).Warnf("清理用户 %d 的 session 失败: %v", userId, logoutErr)
+			}
+		}
+		return nil, err
 	}
 
-	return ctx.JSON(result.NewSuccessResult(user))
+	return user, nil
 }
 
-func (u *UserController) SetupRouter(router fiber.Router, permissionMiddleware fiber.Handler) {
+func (u *UserController) SetupRouter(router fiber.Router, requirePermission middleware.RequirePermission) {
 	userAPI := router.Group("/user")
-	userAPI.Post("/v1/register", u.Register)
-	userAPI.Get("/v1/info", permissionMiddleware, u.GetUserInfoByUsername)
+	Route(userAPI, fiber.MethodPost, "/v1/register", u.base, u.Register)
+	Route(userAPI, fiber.MethodPost, "/v1/login", u.base, u.Login)
+	Route(userAPI, fiber.MethodPost, "/v1/logout", u.base, u.Logout)
+	Route(userAPI, fiber.MethodGet, "/v1/me", u.base, u.CurrentUser)
+	Route(userAPI, fiber.MethodGet, "/v1/info", u.base, u.GetUserInfoByUsername, requirePermission(constant.PermissionUserRead))
+	Route(userAPI, fiber.MethodGet, "/v1/list", u.base, u.ListUsers, requirePermission(constant.PermissionUserRead))
 }
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"my-web-template/internal/logging"
+)
+
+// disconnectCheckInterval handler 执行期间检测客户端连接的间隔
+const disconnectCheckInterval = 200 * time.Millisecond
+
+// CancelOnDisconnect 客户端断开连接后取消 UserContext，进行中的数据库查询会被取消。
+// fasthttp 在 handler 执行期间不会检测连接状态，这里在后台定期用 MSG_PEEK 读取连接：
+// 读到 EOF 或连接错误说明客户端已经关闭连接；peek 不会消费数据，不影响同一连接上的下一个请求。
+// 只对明文的 TCP、unix socket 连接生效，TLS 连接和不支持的平台不做检测，只受 Timeout 限制。
+// 发送请求后半关闭连接（shutdown write）的客户端会被当作已断开。
+func CancelOnDisconnect() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		conn, ok := c.Context().Conn().(syscall.Conn)
+		if !ok {
+			return c.Next()
+		}
+		rawConn, err := conn.SyscallConn()
+		if err != nil {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithCancel(c.UserContext())
+		defer cancel()
+		c.SetUserContext(ctx)
+		// fiber.Ctx 不是并发安全的，后台 goroutine 只使用这里取出的值
+		logger, path := logging.FromContext(ctx), c.Path()
+
+		done := make(chan struct{})
+		stopped := make(chan struct{})
+		go func() {
+			defer close(stopped)
+			ticker := time.NewTicker(disconnectCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if peerClosed(rawConn) {
+						logger.Infof("客户端已断开连接，取消请求 %s", path)
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+		// handler 返回后等待检测结束，之后连接可能被 fasthttp 复用
+		defer func() {
+			close(done)
+			<-stopped
+		}()
+
+		return c.Next()
+	}
+}
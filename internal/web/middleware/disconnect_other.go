@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package middleware
+
+import "syscall"
+
+// peerClosed 不支持的平台不检测连接状态
+func peerClosed(syscall.RawConn) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin
+
+package middleware
+
+import (
+	"errors"
+	"syscall"
+)
+
+// peerClosed 不阻塞地 peek 连接中的一个字节，对端已经关闭或连接出错时返回 true
+func peerClosed(conn syscall.RawConn) bool {
+	closed := false
+	buf := make([]byte, 1)
+	err := conn.Read(func(fd uintptr) bool {
+		n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		switch {
+		case err == nil:
+			closed = n == 0
+		case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EINTR):
+		default:
+			closed = true
+		}
+		return true
+	})
+	return closed || err != nil
+}
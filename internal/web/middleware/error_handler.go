@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/result"
+)
+
+// internalErrorMessage 非 debug 模式下替代服务端错误的详细信息
+const internalErrorMessage = "internal server error"
+
+// ErrorHandler 全局错误处理，将 handler 返回的 result.AppError、*fiber.Error、
+// Recover 捕获的 panic 以及其他错误统一渲染为 AppResult。
+// HTTP 状态码由 AppResult.Code 决定，forceHTTP200 为 true 时统一返回 200；
+// 非 debug 模式下隐藏服务端错误的详细信息和调用栈。
+func ErrorHandler(debug bool, forceHTTP200 bool, logger *zap.SugaredLogger) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		logger := GetLogger(c, logger)
+		status := fiber.StatusInternalServerError
+		var appResult *result.AppResult
+
+		var appErr result.AppError
+		var fiberErr *fiber.Error
+		var panicErr *PanicError
+		switch {
+		case errors.As(err, &appErr):
+			appResult = appErr.ToAppResult()
+			status = constant.GetResultCodeHTTPStatus(appResult.Code)
+			if isServerError(appResult.Code) {
+				logger.Errorf("请求处理失败 - %s %s, IP: %s, Error: %v", c.Method(), c.Path(), c.IP(), err)
+			} else {
+				logger.Infof("请求处理失败 - %s %s, IP: %s, Code: %d, Message: %s", c.Method(), c.Path(), c.IP(), appResult.Code, appResult.Message)
+			}
+		case errors.As(err, &fiberErr):
+			status = fiberErr.Code
+			appResult = result.NewErrorResult(codeFromHTTPStatus(fiberErr.Code), fiberErr.Message)
+			if status >= fiber.StatusInternalServerError {
+				logger.Errorf("请求处理失败 - %s %s, IP: %s, Status: %d, Error: %v", c.Method(), c.Path(), c.IP(), status, err)
+			} else {
+				logger.Infof("请求处理失败 - %s %s, IP: %s, Status: %d, Error: %v", c.Method(), c.Path(), c.IP(), status, err)
+			}
+		case errors.As(err, &panicErr):
+			appResult = result.NewErrorResult(constant.CodeRuntimeError, panicErr.Error())
+			appResult.ErrorStack = panicErr.Stack
+			logger.Errorf("请求处理发生 panic - %s %s, IP: %s, Error: %v\n%s", c.Method(), c.Path(), c.IP(), panicErr.Value, panicErr.Stack)
+		default:
+			appResult = result.NewErrorResult(constant.CodeUnknownError, err.Error())
+			logger.Errorf("请求处理失败 - %s %s, IP: %s, Error: %v", c.Method(), c.Path(), c.IP(), err)
+		}
+
+		if !debug {
+			appResult.ErrorStack = ""
+			if isServerError(appResult.Code) {
+				appResult.Message = internalErrorMessage
+			}
+		}
+
+		appResult.RequestId = GetRequestID(c)
+		c.Locals(constant.LocalsKeyResultCode, appResult.Code)
+		if forceHTTP200 {
+			status = fiber.StatusOK
+		}
+		return c.Status(status).JSON(appResult)
+	}
+}
+
+// isServerError 服务端错误的详细信息可能包含 SQL、路径等敏感内容
+func isServerError(code constant.ResultCode) bool {
+	return code == constant.CodeDBError || code == constant.CodeRuntimeError || code == constant.CodeUnknownError
+}
+
+func codeFromHTTPStatus(status int) constant.ResultCode {
+	switch {
+	case status == fiber.StatusNotFound:
+		return constant.CodeNotFound
+	case status == fiber.StatusMethodNotAllowed:
+		return constant.CodeMethodNotAllowed
+	case status == fiber.StatusUnauthorized:
+		return constant.CodeNotLogin
+	case status == fiber.StatusForbidden:
+		return constant.CodeNoPermission
+	case status >= fiber.StatusInternalServerError:
+		return constant.CodeRuntimeError
+	default:
+		return constant.CodeParamError
+	}
+}
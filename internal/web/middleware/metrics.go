@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/metrics"
+)
+
+// unmatchedRoute 没有匹配到任何路由（404）的请求使用的 route label
+const unmatchedRoute = "unmatched"
+
+// Metrics 记录每个请求的数量、处理时间和返回的 AppResult.code。
+// 需要放在 Recover 之前，这样 panic 的请求也能被记录；handler 返回的错误会在这里交给 ErrorHandler 渲染，
+// 以便拿到最终的状态码。
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// 使用路由模板而不是实际路径作为 label。没有匹配到路由时，c.Route() 是最后经过的
+		// 全局中间件，路径为 /，这种情况单独标记，避免和真正的 / 路由混在一起
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		if status == fiber.StatusNotFound && route == "/" {
+			route = unmatchedRoute
+		}
+		// c.Method() 引用的是 fasthttp 会复用的内存，作为 label 保存前需要复制
+		metrics.ObserveRequest(utils.CopyString(c.Method()), route, status, time.Since(start))
+
+		if code, ok := c.Locals(constant.LocalsKeyResultCode).(constant.ResultCode); ok {
+			metrics.ObserveResultCode(code)
+		}
+		return nil
+	}
+}
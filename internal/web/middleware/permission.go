@@ -1,23 +1,101 @@
 package middleware
 
 import (
+	"context"
+	"slices"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/result"
 	"my-web-template/internal/service"
 )
 
-func PermissionMiddleware(userService service.UserServiceInterface) fiber.Handler {
-	return func(c *fiber.Ctx) error {
+// RequirePermission 注册路由时声明需要的权限，返回对应的中间件。
+// 需要同时拥有所有列出的权限才能访问；不传权限时只要求登录。
+// 无论是否声明权限，用户被禁用或删除后都不能继续访问。
+type RequirePermission func(permissions ...string) fiber.Handler
 
-		// 调用服务层的方法
-		// userService.GetUserByUsername("admin")
+func PermissionMiddleware(sessionStore *session.Store, userService service.UserServiceInterface, roleService service.RoleServiceInterface, logger *zap.SugaredLogger) RequirePermission {
+	return func(permissions ...string) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			sess, err := sessionStore.Get(c)
+			if err != nil {
+				return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+			}
 
-		// 如果想中断当前请求
-		// return c.JSON(fiber.Map{"message": "权限不足"})
+			userId, ok := sess.Get(constant.SessionKeyUserId).(uint64)
+			if !ok || userId == 0 {
+				return result.NewAppError(constant.CodeNotLogin, "not logged in")
+			}
+			c.Locals(constant.LocalsKeyUserId, userId)
 
-		// 把数据放到 ctx 中
-		// c.Locals("user", userService.GetCurrentUser(c))
+			owned, appErr := loadPermissions(c.UserContext(), sess, userService, roleService, userId)
+			if appErr != nil {
+				// 用户已被删除或禁用时清理 session，数据库错误等临时故障不会让用户掉线
+				code := appErr.ToAppResult().Code
+				if code == constant.CodeRecordNotFound || code == constant.CodeUserDisabled {
+					if err := sess.Destroy(); err != nil {
+						return result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+					}
+				}
+				if code == constant.CodeRecordNotFound {
+					return result.NewAppError(constant.CodeNotLogin, "not logged in")
+				}
+				return appErr
+			}
+			if !hasPermissions(owned, permissions) {
+				GetLogger(c, logger).Infof("用户 %d 缺少权限 %v，拒绝访问 %s", userId, permissions, c.Path())
+				return result.NewAppError(constant.CodeNoPermission, "permission denied")
+			}
 
-		// 继续执行
-		return c.Next()
+			return c.Next()
+		}
 	}
 }
+
+// loadPermissions 优先使用 session 中缓存的权限，权限版本变化后从数据库重新加载。
+// 禁用、删除、恢复用户都会更新权限版本，所以重新加载前先检查用户状态：
+// 用户不存在返回 CodeRecordNotFound，被禁用返回 CodeUserDisabled，这两种情况都不会写入缓存。
+func loadPermissions(ctx context.Context, sess *session.Session, userService service.UserServiceInterface, roleService service.RoleServiceInterface, userId uint64) ([]string, result.AppError) {
+	version, err := roleService.GetPermissionVersion(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, ok := sess.Get(constant.SessionKeyPermissions).([]string)
+	cachedVersion, versionOk := sess.Get(constant.SessionKeyPermissionVersion).(string)
+	if ok && versionOk && cachedVersion == version {
+		return cached, nil
+	}
+
+	if _, err := userService.GetUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	permissions, err := roleService.GetUserPermissions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	sess.Set(constant.SessionKeyPermissions, permissions)
+	sess.Set(constant.SessionKeyPermissionVersion, version)
+	if err := sess.Save(); err != nil {
+		return nil, result.NewAppErrorFromError(constant.CodeRuntimeError, err, true)
+	}
+
+	return permissions, nil
+}
+
+func hasPermissions(owned []string, required []string) bool {
+	if slices.Contains(owned, constant.PermissionAll) {
+		return true
+	}
+	for _, permission := range required {
+		if !slices.Contains(owned, permission) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PanicError 由 Recover 捕获的 panic，交给 ErrorHandler 统一处理
+type PanicError struct {
+	Value any
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover 捕获后续 handler 中的 panic，转换成 *PanicError 返回，
+// 和 fiber 自带的 recover 中间件相比保留了 panic 的值和调用栈
+func Recover() fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: string(debug.Stack())}
+			}
+		}()
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"my-web-template/internal/constant"
+	"my-web-template/internal/logging"
+)
+
+// HeaderRequestID 请求和响应中携带 request id 的 header
+const HeaderRequestID = "X-Request-ID"
+
+// validRequestID 只接受长度有限的安全字符，避免客户端通过 header 向日志中注入内容
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID 使用客户端传入的 X-Request-ID，没有或不合法时生成新的 ID。
+// ID 会写入响应头，并创建带有 request_id 字段的子 logger，
+// 同时保存到 Locals 和 UserContext 中，供 controller、service、repository 使用。
+func RequestID(logger *zap.SugaredLogger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestId := c.Get(HeaderRequestID)
+		if !validRequestID.MatchString(requestId) {
+			requestId = uuid.NewString()
+		}
+
+		requestLogger := logger.With("request_id", requestId)
+		c.Locals(constant.LocalsKeyRequestId, requestId)
+		c.Locals(constant.LocalsKeyLogger, requestLogger)
+		c.SetUserContext(logging.WithLogger(c.UserContext(), requestLogger))
+		c.Set(HeaderRequestID, requestId)
+
+		return c.Next()
+	}
+}
+
+// GetRequestID 获取当前请求的 request id，未经过 RequestID 中间件时返回空字符串
+func GetRequestID(c *fiber.Ctx) string {
+	requestId, _ := c.Locals(constant.LocalsKeyRequestId).(string)
+	return requestId
+}
+
+// GetLogger 获取当前请求的 logger，未经过 RequestID 中间件时返回 fallback
+func GetLogger(c *fiber.Ctx, fallback *zap.SugaredLogger) *zap.SugaredLogger {
+	if requestLogger, ok := c.Locals(constant.LocalsKeyLogger).(*zap.SugaredLogger); ok {
+		return requestLogger
+	}
+	return fallback
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Timeout 给 UserContext 设置处理超时，controller 把 ctx.UserContext() 传给 service、repository，
+// 超时后进行中的数据库查询会被取消，repository 返回 CodeRequestTimeout（HTTP 504）。
+// 客户端断开连接时由 CancelOnDisconnect 提前取消。
+// 需要放在 RequestID 之后，才能保留 UserContext 中的请求级 logger。
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+
+		return c.Next()
+	}
+}